@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"github.com/Zach51920/discord-bot/config"
+	"github.com/Zach51920/discord-bot/filehost"
 	"github.com/Zach51920/discord-bot/postgres"
+	"github.com/Zach51920/discord-bot/youtube"
 	"github.com/bwmarrin/discordgo"
 	ranna "github.com/ranna-go/ranna/pkg/client"
 	"io"
@@ -22,6 +24,8 @@ type Bot struct {
 	sess       *discordgo.Session
 	dbProvider *postgres.Provider
 	rClient    ranna.Client
+	fileHost   *filehost.Host
+	ytClient   *youtube.Client
 
 	closers []io.Closer
 	wg      sync.WaitGroup
@@ -66,6 +70,13 @@ func (b *Bot) Shutdown() {
 	}()
 
 	<-ctx.Done()
+
+	// close handlers while the session is still open, they may need it to clean up (ie. restore server mutes)
+	for _, closer := range b.closers {
+		if err := closer.Close(); err != nil {
+			slog.Error("failed to close handler", "error", err)
+		}
+	}
 	if err := b.sess.Close(); err != nil {
 		slog.Error("failed to close session", "error", err)
 	}
@@ -89,6 +100,19 @@ func (b *Bot) init() error {
 		return fmt.Errorf("create postgres provider: %w", err)
 	}
 
+	// init youtube client, shared by all handlers so they share the search cache, quota and download cache
+	if b.ytClient, err = youtube.New(b.config.YouTube, b.dbProvider.Get(), b.sendAlert); err != nil {
+		return fmt.Errorf("create youtube client: %w", err)
+	}
+
+	// init file host
+	if b.config.FileHost.Enabled {
+		if b.fileHost, err = filehost.New(b.config.FileHost, config.GetString("FILE_HOST_SECRET")); err != nil {
+			return fmt.Errorf("create file host: %w", err)
+		}
+		b.fileHost.Start()
+	}
+
 	// init bot
 	token := config.GetString("BOT_TOKEN")
 	b.sess, err = discordgo.New("Bot " + token)
@@ -5,6 +5,17 @@ import (
 	"log/slog"
 )
 
+var tsOrderChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "Random", Value: "random"},
+	{Name: "Join order", Value: "join"},
+	{Name: "Alphabetical", Value: "alphabetical"},
+	{Name: "Role priority", Value: "role-priority"},
+	{Name: "Least recently spoke", Value: "least-recent"},
+}
+
+// minSearchResults is a var since discord option minimums are pointers
+var minSearchResults = 1.0
+
 var commands = []*discordgo.ApplicationCommand{
 	{
 		Name:        "coinflip",
@@ -29,10 +40,168 @@ var commands = []*discordgo.ApplicationCommand{
 		Description: "Manage a talking stick session in your current voice channel",
 		Options: []*discordgo.ApplicationCommandOption{
 			{
-				Type:        discordgo.ApplicationCommandOptionInteger,
-				Name:        "duration",
-				Description: "Duration each user holds the talking stick (in seconds, default: 15)",
-				Required:    false,
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "start",
+				Description: "Start a talking stick session in your current voice channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "duration",
+						Description: "Duration each user holds the talking stick (in seconds, default: server setting)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "strict",
+						Description: "Server mute everyone except the person holding the talking stick (default: server setting)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "mode",
+						Description: "How the talking stick gets passed around (default: round-robin)",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Round-robin", Value: "round-robin"},
+							{Name: "Raise hand queue", Value: "queue"},
+							{Name: "Debate", Value: "debate"},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "order",
+						Description: "Order the talking stick gets passed around in (default: server setting)",
+						Required:    false,
+						Choices:     tsOrderChoices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "skip-muted",
+						Description: "Skip members that muted themselves (default: false)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "skip-deafened",
+						Description: "Skip members that deafened themselves (default: false)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "skip-away",
+						Description: "Skip members that are idle or left the channel (default: false)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "opening",
+						Description: "Debate mode: seconds each debater gets in the opening phase (default: duration)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "rebuttal",
+						Description: "Debate mode: seconds each debater gets in the rebuttal phase (default: duration)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "closing",
+						Description: "Debate mode: seconds each debater gets in the closing phase (default: duration)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "moderator",
+						Description: "Debate mode: member that runs the debate instead of taking part in it",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "stats",
+				Description: "Show all-time talking stick stats for this server",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "stop",
+				Description: "End the talking stick session in your current voice channel",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "status",
+				Description: "Show who is holding the talking stick and how much time they have left",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "skip",
+				Description: "Pass the talking stick to the next speaker",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "order",
+				Description: "Show the speaking order",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "panel",
+				Description: "Repost the control panel at the bottom of the channel",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "pause",
+				Description: "Pause the talking stick session",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "resume",
+				Description: "Resume a paused talking stick session",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "settings",
+				Description: "View or change the talking stick defaults for this server",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "duration",
+						Description: "Default duration each user holds the talking stick (in seconds)",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "inactivity-timeout",
+						Description: "Minutes without any interaction before a session is closed",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "order",
+						Description: "Default speaking order",
+						Required:    false,
+						Choices:     tsOrderChoices,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "strict",
+						Description: "Use strict mode by default",
+						Required:    false,
+					},
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "panel-channel",
+						Description:  "Channel to post control panels in",
+						Required:     false,
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "reset-panel-channel",
+						Description: "Post control panels in the voice channel's chat again",
+						Required:    false,
+					},
+				},
 			},
 		},
 	},
@@ -43,7 +212,7 @@ var commands = []*discordgo.ApplicationCommand{
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "url",
-				Description: "VideoID of the YouTube video to download.",
+				Description: "URL or ID of the YouTube video to download.",
 				Required:    false,
 			},
 			{
@@ -52,6 +221,34 @@ var commands = []*discordgo.ApplicationCommand{
 				Description: "Search query for the YouTube video to download.",
 				Required:    false,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "format",
+				Description: "Download the video or only its audio, defaults to video.",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Video", Value: "video"},
+					{Name: "Audio", Value: "audio"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "quality",
+				Description: "Video resolution (ie. 720p) or audio quality (high, medium, low), defaults to the best.",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "start",
+				Description: "Clip the download starting at this timestamp (ie. 1:23).",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "end",
+				Description: "Clip the download ending at this timestamp (ie. 1:43).",
+				Required:    false,
+			},
 		},
 	},
 	{
@@ -64,6 +261,195 @@ var commands = []*discordgo.ApplicationCommand{
 				Description: "Search query to find YouTube videos.",
 				Required:    true,
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "type",
+				Description: "Type of result to search for, defaults to everything.",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Video", Value: "video"},
+					{Name: "Playlist", Value: "playlist"},
+					{Name: "Channel", Value: "channel"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "duration",
+				Description: "Only find videos of this length.",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Short (under 4 minutes)", Value: "short"},
+					{Name: "Medium (4 to 20 minutes)", Value: "medium"},
+					{Name: "Long (over 20 minutes)", Value: "long"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "uploaded",
+				Description: "Only find results uploaded recently.",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Last hour", Value: "hour"},
+					{Name: "Today", Value: "today"},
+					{Name: "This week", Value: "week"},
+					{Name: "This month", Value: "month"},
+					{Name: "This year", Value: "year"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "order",
+				Description: "How to order the results, defaults to relevance.",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Relevance", Value: "relevance"},
+					{Name: "Upload date", Value: "date"},
+					{Name: "View count", Value: "viewCount"},
+					{Name: "Rating", Value: "rating"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "max-results",
+				Description: "How many results to show per page, only the first 4 get download buttons.",
+				Required:    false,
+				MinValue:    &minSearchResults,
+				MaxValue:    10,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "safe-search",
+				Description: "Filter restricted content, defaults to moderate.",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "None", Value: "none"},
+					{Name: "Moderate", Value: "moderate"},
+					{Name: "Strict", Value: "strict"},
+				},
+			},
+		},
+	},
+	{
+		Name:        "yt-info",
+		Description: "Show the details of a YouTube video.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "url",
+				Description: "URL or ID of the YouTube video.",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "query",
+				Description: "Search query for the YouTube video.",
+				Required:    false,
+			},
+		},
+	},
+	{
+		Name:        "yt-transcript",
+		Description: "Download the captions of a YouTube video.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "url",
+				Description: "URL or ID of the YouTube video.",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "query",
+				Description: "Search query for the YouTube video.",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "language",
+				Description: "Language code of the captions (ie. en), defaults to the video's captions.",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "format",
+				Description: "File format of the transcript, defaults to text.",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Text", Value: "text"},
+					{Name: "SRT", Value: "srt"},
+					{Name: "VTT", Value: "vtt"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "start",
+				Description: "Only include captions after this timestamp (ie. 1:23).",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "end",
+				Description: "Only include captions before this timestamp (ie. 1:43).",
+				Required:    false,
+			},
+		},
+	},
+	{
+		Name:        "yt-playlist",
+		Description: "List or download the videos in a YouTube playlist.",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "info",
+				Description: "List the videos in a playlist.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "URL or ID of the YouTube playlist.",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "download",
+				Description: "Download videos from a playlist.",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "URL or ID of the YouTube playlist.",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "entries",
+						Description: "Entries to download (ie. 1-3,5), defaults to the start of the playlist.",
+						Required:    false,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "format",
+						Description: "Download the videos or only their audio, defaults to video.",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Video", Value: "video"},
+							{Name: "Audio", Value: "audio"},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "delivery",
+						Description: "Send the videos in a single zip or one message each, defaults to zip.",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{Name: "Zip", Value: "zip"},
+							{Name: "Queue", Value: "queue"},
+						},
+					},
+				},
+			},
 		},
 	},
 	{
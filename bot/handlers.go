@@ -7,13 +7,17 @@ import (
 )
 
 func (b *Bot) RegisterHandlers() {
-	interaction := interactions.New(b.sess)
-	event := events.New(b.sess, b.rClient, b.dbProvider.Get())
+	interaction := interactions.New(b.sess, b.dbProvider.Get(), b.ytClient, b.fileHost)
+	event := events.New(b.sess, b.rClient, b.ytClient, b.dbProvider.Get())
 
-	b.closers = append(b.closers, interaction, event)
+	b.closers = append(b.closers, interaction, event, b.ytClient) // after handlers, they may still be reading downloads
+	if b.fileHost != nil {
+		b.closers = append(b.closers, b.fileHost) // after interactions, they may still be storing files
+	}
 
 	b.sess.AddHandler(interaction.HandleCommand)
 	b.sess.AddHandler(interaction.HandleButtons)
+	b.sess.AddHandler(interaction.HandleVoiceStateUpdate)
 	b.sess.AddHandler(event.HandleMessageCreate)
 	b.sess.AddHandler(event.HandleMessageUpdate)
 	b.sess.AddHandler(event.HandleReactionAdd)
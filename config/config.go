@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
+	"time"
 )
 
 type Config struct {
-	Bot    BotConfig    `yaml:"bot"`
-	Ranna  RannaConfig  `yaml:"ranna"`
-	Logger LoggerConfig `yaml:"logger"`
+	Bot      BotConfig      `yaml:"bot"`
+	Ranna    RannaConfig    `yaml:"ranna"`
+	Logger   LoggerConfig   `yaml:"logger"`
+	FileHost FileHostConfig `yaml:"file_host"`
+	YouTube  YouTubeConfig  `yaml:"youtube"`
 }
 
 type BotConfig struct {
@@ -31,6 +34,34 @@ type LoggerConfig struct {
 	Format  string `yaml:"format"`
 }
 
+// FileHostConfig configures the http server that hosts downloads too large to upload to discord
+type FileHostConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Address the server listens on, ie. :8081
+	Address string `yaml:"address"`
+	// BaseURL the server is publicly reachable at, links to hosted files are built from it
+	BaseURL   string `yaml:"base_url"`
+	Directory string `yaml:"directory"`
+	// QuotaMB is how much disk space hosted files can take up
+	QuotaMB int64         `yaml:"quota_mb"`
+	TTL     time.Duration `yaml:"ttl"`
+}
+
+// YouTubeConfig configures how the bot spends its YouTube Data API quota and caches downloads
+type YouTubeConfig struct {
+	// SearchCacheTTL is how long search results are reused, searches aren't cached when 0
+	SearchCacheTTL time.Duration `yaml:"search_cache_ttl"`
+	// PersistSearchCache keeps cached search results in postgres so they survive restarts
+	PersistSearchCache bool `yaml:"persist_search_cache"`
+	// DailyQuota is how many Data API units can be spent a day, usage isn't limited when 0
+	DailyQuota int `yaml:"daily_quota"`
+	// QuotaAlertThresholds are the fractions of the daily quota that send an alert when crossed
+	QuotaAlertThresholds []float64 `yaml:"quota_alert_thresholds"`
+	// DownloadCacheMB is how much disk space cached downloads can take up, downloads aren't cached when 0
+	DownloadCacheMB        int64  `yaml:"download_cache_mb"`
+	DownloadCacheDirectory string `yaml:"download_cache_directory"`
+}
+
 func Load(filepath string) (Config, error) {
 	yamlFile, err := os.ReadFile(filepath)
 	if err != nil {
@@ -28,6 +28,14 @@ func GetString(key string) string {
 	return v
 }
 
+// GetStringDefault returns the value of an optional key, or def if it isn't set
+func GetStringDefault(key, def string) string {
+	if v, ok := configMap[key]; ok && v != "" {
+		return v
+	}
+	return def
+}
+
 func GetInt(key string) int {
 	v, ok := configMap[key]
 	if !ok {
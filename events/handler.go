@@ -1,6 +1,8 @@
 package events
 
 import (
+	"github.com/Zach51920/discord-bot/media"
+	"github.com/Zach51920/discord-bot/youtube"
 	"github.com/bwmarrin/discordgo"
 	"github.com/jmoiron/sqlx"
 	ranna "github.com/ranna-go/ranna/pkg/client"
@@ -8,23 +10,33 @@ import (
 )
 
 type Handler struct {
-	db      *sqlx.DB
-	rClient ranna.Client
+	db       *sqlx.DB
+	rClient  ranna.Client
+	ytClient *youtube.Client
+	resolver *media.Chain
 
 	sess *discordgo.Session
 	wg   sync.WaitGroup
 
-	messageCh  chan *discordgo.Message
+	messageCh  chan messageEvent
 	shutdownCh chan struct{}
 }
 
-func New(sess *discordgo.Session, rClient ranna.Client, db *sqlx.DB) *Handler {
+// messageEvent is a message that was created or updated
+type messageEvent struct {
+	message *discordgo.Message
+	created bool
+}
+
+func New(sess *discordgo.Session, rClient ranna.Client, ytClient *youtube.Client, db *sqlx.DB) *Handler {
 	handler := &Handler{
 		db:         db,
 		rClient:    rClient,
+		ytClient:   ytClient,
+		resolver:   media.NewChain(media.YouTube{}),
 		sess:       sess,
 		wg:         sync.WaitGroup{},
-		messageCh:  make(chan *discordgo.Message),
+		messageCh:  make(chan messageEvent),
 		shutdownCh: make(chan struct{}),
 	}
 	go handler.Start()
@@ -35,7 +47,7 @@ func (h *Handler) Start() {
 	for {
 		select {
 		case m := <-h.messageCh:
-			h.handleMessage(m)
+			h.handleMessage(m.message, m.created)
 		case <-h.shutdownCh:
 			return
 		}
@@ -44,7 +56,6 @@ func (h *Handler) Start() {
 
 func (h *Handler) Close() error {
 	h.wg.Wait()
-	close(h.messageCh)
 	close(h.shutdownCh)
 	return nil
 }
@@ -12,12 +12,12 @@ const codeExecEmoji = "⚡"
 
 func (h *Handler) HandleMessageCreate(s *discordgo.Session, e *discordgo.MessageCreate) {
 	slog.Debug("intercepted message create", "message", e.ID)
-	h.HandleMessage(s, e.Message)
+	h.queueMessage(e.Message, true)
 }
 
 func (h *Handler) HandleMessageUpdate(s *discordgo.Session, e *discordgo.MessageUpdate) {
 	slog.Debug("intercepted message update", "message", e.ID)
-	h.HandleMessage(s, e.Message)
+	h.queueMessage(e.Message, false)
 }
 
 func (h *Handler) HandleReactionAdd(s *discordgo.Session, e *discordgo.MessageReactionAdd) {
@@ -41,16 +41,16 @@ func (h *Handler) HandleReactionAdd(s *discordgo.Session, e *discordgo.MessageRe
 	h.executeCodeBlock(message, e.UserID)
 }
 
-func (h *Handler) HandleMessage(s *discordgo.Session, e *discordgo.Message) {
-	if e.Author.Bot || e.Content == "" {
+func (h *Handler) queueMessage(e *discordgo.Message, created bool) {
+	if e.Author == nil || e.Author.Bot || e.Content == "" {
 		return
 	}
 
 	slog.Debug("adding message to messageCh", "message", e.ID)
-	h.messageCh <- e
+	h.messageCh <- messageEvent{message: e, created: created}
 }
 
-func (h *Handler) handleMessage(e *discordgo.Message) {
+func (h *Handler) handleMessage(e *discordgo.Message, created bool) {
 	h.wg.Add(1)
 	defer h.wg.Done()
 
@@ -58,6 +58,12 @@ func (h *Handler) handleMessage(e *discordgo.Message) {
 		h.handleCodeBlock(e)
 		return
 	}
+	// only preview new messages. Edits, discord unfurling its own embed, and the bot suppressing that embed all
+	// arrive as updates and shouldn't post another preview
+	if refs := h.findYouTubeLinks(e.Content); len(refs) != 0 && created {
+		h.handleYouTubeLinks(e, refs)
+		return
+	}
 	slog.Debug("not a message of interest, discarding...")
 	return
 }
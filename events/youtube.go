@@ -0,0 +1,158 @@
+package events
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Zach51920/discord-bot/media"
+	"github.com/Zach51920/discord-bot/youtube"
+	"github.com/bwmarrin/discordgo"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// maxPreviews is the most links previewed in a single message
+const maxPreviews = 3
+
+// maxPreviewChapters is the most chapters listed on a preview card
+const maxPreviewChapters = 5
+
+// linkPattern matches URLs, links wrapped in <> are matched with the bracket so they can be skipped
+var linkPattern = regexp.MustCompile(`<?https?://[^\s<>]+>?`)
+
+type previewSettings struct {
+	Enabled        bool `db:"youtube_previews"`
+	SuppressEmbeds bool `db:"youtube_suppress_embeds"`
+}
+
+// findYouTubeLinks returns the videos linked in the message content. Links wrapped in <> are skipped since the
+// author asked discord not to embed them
+func (h *Handler) findYouTubeLinks(content string) []media.Reference {
+	refs := make([]media.Reference, 0)
+	seen := make(map[string]bool)
+	for _, link := range linkPattern.FindAllString(content, -1) {
+		if strings.HasPrefix(link, "<") && strings.HasSuffix(link, ">") {
+			continue
+		}
+		ref, err := h.resolver.Resolve(strings.Trim(link, "<>"))
+		if err != nil || ref.Site != media.SiteYouTube || ref.ID == "" || seen[ref.ID] {
+			continue
+		}
+		seen[ref.ID] = true
+		refs = append(refs, ref)
+		if len(refs) == maxPreviews {
+			break
+		}
+	}
+	return refs
+}
+
+func (h *Handler) handleYouTubeLinks(e *discordgo.Message, refs []media.Reference) {
+	// check if previews are enabled for this channel
+	var settings previewSettings
+	query := `SELECT youtube_previews, youtube_suppress_embeds FROM channels WHERE guild_id = $1 AND channel_id = $2`
+	if err := h.db.Get(&settings, query, e.GuildID, e.ChannelID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("failed to get youtube preview settings", "message", e.ID, "error", err)
+		return
+	}
+	if !settings.Enabled {
+		slog.Debug("youtube previews are disabled for this channel", "channel", e.ChannelID)
+		return
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, 0, len(refs))
+	components := make([]discordgo.MessageComponent, 0, len(refs))
+	for _, ref := range refs {
+		info, err := h.ytClient.GetVideo(ref.ID)
+		if err != nil {
+			slog.Warn("failed to get video for preview", "message", e.ID, "video_id", ref.ID, "error", err)
+			continue
+		}
+		embeds = append(embeds, mapPreviewCard(info))
+		components = append(components, mapPreviewButtons(info, len(refs) > 1))
+	}
+	if len(embeds) == 0 {
+		return
+	}
+
+	h.sess.Lock()
+	defer h.sess.Unlock()
+	if _, err := h.sess.ChannelMessageSendComplex(e.ChannelID, &discordgo.MessageSend{
+		Embeds:          embeds,
+		Components:      components,
+		Reference:       e.Reference(),
+		AllowedMentions: &discordgo.MessageAllowedMentions{}, // don't ping the author
+	}); err != nil {
+		slog.Error("failed to send youtube preview", "message", e.ID, "error", err)
+		return
+	}
+
+	if settings.SuppressEmbeds {
+		if err := suppressEmbeds(h.sess, e); err != nil {
+			slog.Error("failed to suppress embeds", "message", e.ID, "error", err)
+		}
+	}
+}
+
+// suppressEmbeds removes discord's embeds from someone else's message, this requires the manage messages permission.
+// Only the flags are sent since nothing else about the message can be edited
+func suppressEmbeds(s *discordgo.Session, e *discordgo.Message) error {
+	data := struct {
+		Flags discordgo.MessageFlags `json:"flags"`
+	}{e.Flags | discordgo.MessageFlagsSuppressEmbeds}
+
+	endpoint := discordgo.EndpointChannelMessage(e.ChannelID, e.ID)
+	if _, err := s.RequestWithBucketID("PATCH", endpoint, data, discordgo.EndpointChannelMessage(e.ChannelID, "")); err != nil {
+		return fmt.Errorf("patch message: %w", err)
+	}
+	return nil
+}
+
+func mapPreviewCard(info *youtube.VideoInfo) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Type:  "rich",
+		Title: info.Title,
+		URL:   youtube.WatchURL(info.ID),
+		Color: 0xFF0000, // Red
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Channel", Value: info.Author, Inline: true},
+			{Name: "Duration", Value: youtube.FormatTimestamp(info.Duration), Inline: true},
+		},
+	}
+	if len(info.Chapters) != 0 {
+		chapters := make([]string, 0, maxPreviewChapters+1)
+		for i, chapter := range info.Chapters {
+			if i == maxPreviewChapters {
+				chapters = append(chapters, fmt.Sprintf("...and %d more", len(info.Chapters)-i))
+				break
+			}
+			chapters = append(chapters, fmt.Sprintf("`%s` %s", youtube.FormatTimestamp(chapter.Start), chapter.Title))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: "Chapters", Value: strings.Join(chapters, "\n"), Inline: false,
+		})
+	}
+	if info.ThumbnailURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: info.ThumbnailURL}
+	}
+	return embed
+}
+
+// mapPreviewButtons uses the same custom IDs as the youtube search results so the interaction handlers pick them up
+func mapPreviewButtons(info *youtube.VideoInfo, labelTitle bool) discordgo.MessageComponent {
+	video, audio := "Download", "Audio"
+	if labelTitle {
+		title := info.Title
+		if runes := []rune(title); len(runes) > 40 {
+			title = string(runes[:37]) + "..."
+		}
+		video, audio = "Download: "+title, "Audio: "+title
+	}
+	return discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: video, Style: discordgo.PrimaryButton, CustomID: "yt_download_video:" + info.ID},
+			discordgo.Button{Label: audio, Style: discordgo.SecondaryButton, CustomID: "yt_download_audio:" + info.ID},
+		},
+	}
+}
@@ -0,0 +1,305 @@
+package filehost
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Zach51920/discord-bot/config"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const cleanupInterval = time.Minute
+
+var ErrTooLarge = errors.New("file is larger than the disk quota")
+
+type hostedFile struct {
+	id      string
+	name    string
+	path    string
+	size    int64
+	expires time.Time
+}
+
+// Host serves files that are too large to upload to discord. Files are only reachable through signed links that
+// expire along with the file, expired files are removed from disk
+type Host struct {
+	mu     *sync.Mutex
+	wg     *sync.WaitGroup
+	cfg    config.FileHostConfig
+	secret []byte
+	quota  int64
+	used   int64
+	files  map[string]*hostedFile
+
+	server     *http.Server
+	shutdownCh chan struct{}
+}
+
+// New creates a file host, files left behind by a previous run can't be linked to anymore and are removed. The
+// directory may be shared, only files named like the host's own are ever removed from it
+func New(cfg config.FileHostConfig, secret string) (*Host, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("file host base url is required")
+	}
+	if cfg.QuotaMB <= 0 {
+		cfg.QuotaMB = 1024
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Hour
+	}
+	if cfg.Directory == "" {
+		cfg.Directory = filepath.Join(os.TempDir(), "overlord-files")
+	}
+	if err := os.MkdirAll(cfg.Directory, 0o755); err != nil {
+		return nil, fmt.Errorf("create directory: %w", err)
+	}
+	if err := removeHostedFiles(cfg.Directory); err != nil {
+		return nil, fmt.Errorf("clear directory: %w", err)
+	}
+
+	// links can't outlive the process without a configured secret, that's fine since neither do the files
+	key := []byte(secret)
+	if len(key) == 0 {
+		slog.Warn("no file host secret configured, generating one")
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("generate secret: %w", err)
+		}
+	}
+
+	h := &Host{
+		mu:         &sync.Mutex{},
+		wg:         &sync.WaitGroup{},
+		cfg:        cfg,
+		secret:     key,
+		quota:      cfg.QuotaMB << 20,
+		files:      make(map[string]*hostedFile),
+		shutdownCh: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/files/", h)
+	h.server = &http.Server{Addr: cfg.Address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return h, nil
+}
+
+// Start serving files and cleaning up the expired ones
+func (h *Host) Start() {
+	h.wg.Add(2)
+	go func() {
+		defer h.wg.Done()
+		slog.Info("starting file host", "address", h.cfg.Address)
+		if err := h.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("file host stopped unexpectedly", "error", err)
+		}
+	}()
+	go func() {
+		defer h.wg.Done()
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.shutdownCh:
+				return
+			case <-ticker.C:
+				h.mu.Lock()
+				h.removeExpired()
+				h.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// MaxFileSize is the largest file in bytes the host can store
+func (h *Host) MaxFileSize() int64 {
+	return h.quota
+}
+
+// Store saves the file and returns a signed link to it, the link expires when the file is removed. The oldest files
+// are removed to make room when the disk quota is reached
+func (h *Host) Store(name string, r io.Reader) (string, time.Time, error) {
+	id, err := newID()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	file := &hostedFile{
+		id:      id,
+		name:    filepath.Base(name),
+		path:    filepath.Join(h.cfg.Directory, id),
+		expires: time.Now().Add(h.cfg.TTL),
+	}
+
+	f, err := os.Create(file.path)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("create file: %w", err)
+	}
+	file.size, err = io.Copy(f, io.LimitReader(r, h.quota+1))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.path)
+		return "", time.Time{}, fmt.Errorf("write file: %w", err)
+	}
+	if file.size > h.quota {
+		_ = os.Remove(file.path)
+		return "", time.Time{}, ErrTooLarge
+	}
+
+	h.mu.Lock()
+	h.removeExpired()
+	h.makeRoom(file.size)
+	h.files[id] = file
+	h.used += file.size
+	h.mu.Unlock()
+
+	slog.Info("hosting file", "id", id, "name", file.name, "size", file.size, "expires", file.expires)
+	return h.signedURL(file), file.expires, nil
+}
+
+// ServeHTTP serves the file the signed link points to
+func (h *Host) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/files/"), "/")
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || !hmac.Equal([]byte(query.Get("signature")), []byte(h.sign(id, expires))) {
+		http.Error(w, "invalid link", http.StatusForbidden)
+		return
+	}
+	if time.Now().After(time.Unix(expires, 0)) {
+		http.Error(w, "link has expired", http.StatusGone)
+		return
+	}
+
+	h.mu.Lock()
+	file, ok := h.files[id]
+	h.mu.Unlock()
+	if !ok {
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+
+	f, err := os.Open(file.path)
+	if err != nil {
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": file.name})
+	if disposition == "" {
+		disposition = "attachment" // the name can't be encoded, let the browser name it after the path
+	}
+	w.Header().Set("Content-Disposition", disposition)
+	http.ServeContent(w, r, file.name, time.Time{}, f)
+}
+
+// Close stops the server and removes all hosted files
+func (h *Host) Close() error {
+	close(h.shutdownCh)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := h.server.Shutdown(ctx)
+	h.wg.Wait()
+
+	if rmErr := removeHostedFiles(h.cfg.Directory); rmErr != nil {
+		slog.Error("failed to remove hosted files", "error", rmErr)
+	}
+	return err
+}
+
+func (h *Host) signedURL(file *hostedFile) string {
+	expires := file.expires.Unix()
+	return fmt.Sprintf("%s/files/%s/%s?expires=%d&signature=%s", strings.TrimSuffix(h.cfg.BaseURL, "/"),
+		file.id, url.PathEscape(file.name), expires, h.sign(file.id, expires))
+}
+
+func (h *Host) sign(id string, expires int64) string {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(id + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// removeExpired deletes every file past its TTL. The caller must hold h.mu
+func (h *Host) removeExpired() {
+	now := time.Now()
+	for _, file := range h.files {
+		if now.After(file.expires) {
+			h.remove(file)
+		}
+	}
+}
+
+// makeRoom deletes the oldest files until there is room for size more bytes. The caller must hold h.mu
+func (h *Host) makeRoom(size int64) {
+	if h.used+size <= h.quota {
+		return
+	}
+	files := make([]*hostedFile, 0, len(h.files))
+	for _, file := range h.files {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].expires.Before(files[j].expires) })
+	for _, file := range files {
+		if h.used+size <= h.quota {
+			return
+		}
+		h.remove(file)
+	}
+}
+
+// remove deletes the file from disk. The caller must hold h.mu
+func (h *Host) remove(file *hostedFile) {
+	slog.Debug("removing hosted file", "id", file.id, "name", file.name)
+	if err := os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Error("failed to remove hosted file", "id", file.id, "error", err)
+	}
+	delete(h.files, file.id)
+	h.used -= file.size
+}
+
+// hostedFilePattern matches the names of hosted files, they're stored under their ID
+var hostedFilePattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// removeHostedFiles deletes every hosted file in the directory, leaving anything else in it alone
+func removeHostedFiles(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && hostedFilePattern.MatchString(entry.Name()) {
+			if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,180 @@
+package filehost
+
+import (
+	"bytes"
+	"github.com/Zach51920/discord-bot/config"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHost(t *testing.T, quotaMB int64) *Host {
+	t.Helper()
+	h, err := New(config.FileHostConfig{
+		BaseURL:   "https://files.example.com",
+		Directory: t.TempDir(),
+		QuotaMB:   quotaMB,
+		TTL:       time.Hour,
+	}, "test-secret")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	return h
+}
+
+// get requests the link from the host, ignoring the scheme and host of the link
+func get(t *testing.T, h *Host, link string) *httptest.ResponseRecorder {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid link %q: %v", link, err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, u.RequestURI(), nil))
+	return rec
+}
+
+func TestServeValidLink(t *testing.T) {
+	h := newTestHost(t, 1)
+	link, expires, err := h.Store("clip é.mp4", strings.NewReader("video"))
+	if err != nil {
+		t.Fatalf("Store() unexpected error: %v", err)
+	}
+	if time.Until(expires) <= 0 {
+		t.Errorf("link expires %s, want it in the future", expires)
+	}
+
+	rec := get(t, h, link)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec.Body.String() != "video" {
+		t.Errorf("body = %q, want %q", rec.Body.String(), "video")
+	}
+	_, params, err := mime.ParseMediaType(rec.Header().Get("Content-Disposition"))
+	if err != nil || params["filename"] != "clip é.mp4" {
+		t.Errorf("Content-Disposition = %q, want the filename %q", rec.Header().Get("Content-Disposition"), "clip é.mp4")
+	}
+}
+
+func TestServeTamperedLink(t *testing.T) {
+	h := newTestHost(t, 1)
+	link, _, err := h.Store("clip.mp4", strings.NewReader("video"))
+	if err != nil {
+		t.Fatalf("Store() unexpected error: %v", err)
+	}
+	u, _ := url.Parse(link)
+	query := u.Query()
+
+	// a different signature
+	badSignature := *u
+	q := u.Query()
+	q.Set("signature", strings.Repeat("0", len(query.Get("signature"))))
+	badSignature.RawQuery = q.Encode()
+
+	// a later expiry with the original signature
+	extended := *u
+	q = u.Query()
+	expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	q.Set("expires", strconv.FormatInt(expires+3600, 10))
+	extended.RawQuery = q.Encode()
+
+	// another file's ID with the original signature
+	otherID := *u
+	otherID.Path = "/files/" + strings.Repeat("a", 32) + "/clip.mp4"
+
+	for name, tampered := range map[string]url.URL{"signature": badSignature, "expires": extended, "id": otherID} {
+		if rec := get(t, h, tampered.String()); rec.Code != http.StatusForbidden {
+			t.Errorf("tampered %s: status = %d, want %d", name, rec.Code, http.StatusForbidden)
+		}
+	}
+}
+
+func TestServeExpiredLink(t *testing.T) {
+	h := newTestHost(t, 1)
+	if _, _, err := h.Store("clip.mp4", strings.NewReader("video")); err != nil {
+		t.Fatalf("Store() unexpected error: %v", err)
+	}
+	var file *hostedFile
+	for _, f := range h.files {
+		file = f
+	}
+	file.expires = time.Now().Add(-time.Minute)
+
+	if rec := get(t, h, h.signedURL(file)); rec.Code != http.StatusGone {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusGone)
+	}
+}
+
+func TestStoreEvictsOldestFiles(t *testing.T) {
+	h := newTestHost(t, 1)
+	half := bytes.Repeat([]byte("a"), 600<<10)
+
+	first, _, err := h.Store("first.mp4", bytes.NewReader(half))
+	if err != nil {
+		t.Fatalf("Store() unexpected error: %v", err)
+	}
+	time.Sleep(time.Millisecond) // the second file must expire after the first
+	second, _, err := h.Store("second.mp4", bytes.NewReader(half))
+	if err != nil {
+		t.Fatalf("Store() unexpected error: %v", err)
+	}
+
+	if rec := get(t, h, first); rec.Code != http.StatusNotFound {
+		t.Errorf("first file status = %d, want %d after it was evicted", rec.Code, http.StatusNotFound)
+	}
+	if rec := get(t, h, second); rec.Code != http.StatusOK {
+		t.Errorf("second file status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if h.used != int64(len(half)) {
+		t.Errorf("used = %d, want %d", h.used, len(half))
+	}
+
+	if _, _, err = h.Store("huge.mp4", io.LimitReader(zeros{}, (1<<20)+1)); err != ErrTooLarge {
+		t.Errorf("Store() error = %v, want %v", err, ErrTooLarge)
+	}
+}
+
+func TestNewKeepsUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	unrelated := filepath.Join(dir, "notes.txt")
+	leftover := filepath.Join(dir, strings.Repeat("b", 32))
+	for _, path := range []string{unrelated, leftover} {
+		if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err := New(config.FileHostConfig{BaseURL: "https://files.example.com", Directory: dir}, "test-secret")
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+	if _, err = os.Stat(leftover); !os.IsNotExist(err) {
+		t.Error("a hosted file left behind by a previous run wasn't removed")
+	}
+	if _, err = os.Stat(unrelated); err != nil {
+		t.Errorf("an unrelated file was removed: %v", err)
+	}
+
+	if err = h.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+	if _, err = os.Stat(unrelated); err != nil {
+		t.Errorf("an unrelated file was removed on close: %v", err)
+	}
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -1,44 +1,463 @@
 package interactions
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
 	"fmt"
+	"github.com/Zach51920/discord-bot/media"
 	"github.com/Zach51920/discord-bot/talkingstick"
+	"github.com/Zach51920/discord-bot/youtube"
 	"github.com/bwmarrin/discordgo"
 	"log/slog"
 	"math/rand"
+	"strings"
+	"sync"
 	"time"
 )
 
+// defaultSearchResults leaves room for a row of buttons per result and the Next/Prev buttons
+const defaultSearchResults = maxActionRows - 1
+
 func (h *Handlers) Search(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	opts := NewRequestOptions(i.ApplicationCommandData().Options)
-	query, _ := opts.GetString("query")
+	req := youtube.SearchRequest{MaxResults: defaultSearchResults}
+	req.Query, _ = opts.GetString("query")
+	req.Type, _ = opts.GetString("type")
+	req.Duration, _ = opts.GetString("duration")
+	req.Order, _ = opts.GetString("order")
+	req.SafeSearch, _ = opts.GetString("safe-search")
+	if maxResults, ok := opts.GetInt("max-results"); ok {
+		req.MaxResults = int(maxResults)
+	}
+	req.Uploaded, _ = opts.GetString("uploaded")
 
-	results, err := h.ytClient.Search(query)
+	results, err := h.ytClient.Search(req)
+	if errors.Is(err, youtube.ErrQuotaExhausted) {
+		writeMessage(s, i, "YouTube search has used up its quota for today, try again tomorrow. Downloading by URL still works.")
+		return
+	}
 	if err != nil {
 		slog.Error("failed to search youtube", "error", err)
 		return
 	}
+	if len(results.Items) == 0 {
+		writeMessage(s, i, "No results found.")
+		return
+	}
+	searchID := h.searches.Save(req)
 	embeds := mapYTSearchResults(results)
-	writeResponse(s, i, withEmbeds(embeds))
+	components := mapYTSearchComponents(results, searchID)
+	writeResponse(s, i, withEmbeds(embeds), withComponents(components))
+}
+
+// searchPageButton flips the search results to the page in the custom ID, state is the search ID and page token
+func (h *Handlers) searchPageButton(s *discordgo.Session, i *discordgo.InteractionCreate, state string) {
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
+		slog.Error("failed to respond to interaction", "error", err)
+	}
+
+	searchID, pageToken, _ := strings.Cut(state, ":")
+	req, ok := h.searches.Get(searchID)
+	if !ok {
+		writeResponse(s, i, withMessage("This search has expired, run `/yt-search` again."), withEphemeral())
+		return
+	}
+	req.PageToken = pageToken
+
+	results, err := h.ytClient.Search(req)
+	if errors.Is(err, youtube.ErrQuotaExhausted) {
+		writeResponse(s, i, withMessage("YouTube search has used up its quota for today, try again tomorrow."), withEphemeral())
+		return
+	}
+	if err != nil {
+		slog.Error("failed to search youtube", "error", err)
+		writeResponse(s, i, withMessage("Failed to load the page, try again later."), withEphemeral())
+		return
+	}
+	embeds := mapYTSearchResults(results)
+	components := mapYTSearchComponents(results, searchID)
+
+	s.Lock()
+	defer s.Unlock()
+	if _, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	}); err != nil {
+		msg, _ := getRESTErrorMessage(err)
+		slog.Error("failed to update search results", "error", msg)
+	}
 }
 
 func (h *Handlers) Download(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	opts := NewRequestOptions(i.ApplicationCommandData().Options)
-	videoID, err := h.getVideoIDFromRequest(opts)
+	ref, err := h.getVideoFromRequest(opts)
+	if msg, ok := getVideoRequestMessage(err); ok {
+		writeResponse(s, i, withMessage("Unable to download video, %s.", msg))
+		return
+	}
 	if err != nil {
 		slog.Error("failed to get videoID", "error", err)
 		return
 	}
+	if ref.ID == "" {
+		writeMessage(s, i, "That link is to a playlist, use `/yt-playlist download` to download it.")
+		return
+	}
+
+	format, _ := opts.GetString("format")
+	quality, _ := opts.GetString("quality")
+	clip, err := getClip(opts)
+	if err != nil {
+		writeResponse(s, i, withMessage("Unable to download video, %s.", err.Error()))
+		return
+	}
+	// links to a timestamp mark the start of the clip, unless one was given
+	if _, ok := opts.GetString("start"); !ok {
+		clip.Start = ref.Start
+	}
+	h.sendVideo(s, i, ref.ID, youtube.DownloadOptions{Media: youtube.Media(format), Quality: quality, Clip: clip})
+}
+
+func (h *Handlers) Info(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	opts := NewRequestOptions(i.ApplicationCommandData().Options)
+	ref, err := h.getVideoFromRequest(opts)
+	if msg, ok := getVideoRequestMessage(err); ok {
+		writeResponse(s, i, withMessage("Unable to get video info, %s.", msg))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get videoID", "error", err)
+		return
+	}
+	if ref.ID == "" {
+		writeMessage(s, i, "That link is to a playlist, use `/yt-playlist info` to see what's in it.")
+		return
+	}
+	h.sendVideoInfo(s, i, ref.ID)
+}
 
-	video, err := h.ytClient.Download(videoID)
+func (h *Handlers) Transcript(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	opts := NewRequestOptions(i.ApplicationCommandData().Options)
+	ref, err := h.getVideoFromRequest(opts)
+	if msg, ok := getVideoRequestMessage(err); ok {
+		writeResponse(s, i, withMessage("Unable to get transcript, %s.", msg))
+		return
+	}
 	if err != nil {
-		slog.Error("failed to get video", "error", err)
+		slog.Error("failed to get videoID", "error", err)
+		return
+	}
+	if ref.ID == "" {
+		writeMessage(s, i, "That link is to a playlist, transcripts are only available for videos.")
 		return
 	}
 
+	window, err := getClip(opts)
+	if err == nil && window.End != 0 && window.Start >= window.End {
+		err = fmt.Errorf("%w: the start must be before the end", youtube.ErrInvalidClip)
+	}
+	if err != nil {
+		writeResponse(s, i, withMessage("Unable to get transcript, %s.", err.Error()))
+		return
+	}
+
+	language, _ := opts.GetString("language")
+	transcript, err := h.ytClient.GetTranscript(ref.ID, language)
+	if errors.Is(err, youtube.ErrNoCaptions) || errors.Is(err, youtube.ErrLanguageUnavailable) {
+		writeResponse(s, i, withMessage("Unable to get transcript, %s.", err.Error()))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to get transcript", "video_id", ref.ID, "error", err)
+		return
+	}
+	if !window.IsZero() {
+		if transcript = transcript.Window(window.Start, window.End); len(transcript.Captions) == 0 {
+			writeMessage(s, i, "There are no captions in that part of the video.")
+			return
+		}
+	}
+
+	format, _ := opts.GetString("format")
+	content, contentType, ext := transcript.Format(youtube.TranscriptFormat(format))
+	files := []*discordgo.File{{
+		Name:        strings.Join(strings.Split(transcript.Title, " "), "-") + "-" + transcript.Language + ext,
+		ContentType: contentType,
+		Reader:      strings.NewReader(content),
+	}}
+	writeResponse(s, i, withFiles(files), withMessage("Transcript of [%s](%s)", transcript.Title, youtube.WatchURL(ref.ID)))
+}
+
+// youtubeButton handles the buttons attached to youtube search results, the video ID is stored in the custom ID
+func (h *Handlers) youtubeButton(s *discordgo.Session, i *discordgo.InteractionCreate, name, videoID string) {
+	// downloads can take a while, defer a new message and let the user know we got the request
+	if err := acknowledgeRequest(s, i); err != nil {
+		slog.Error("failed to acknowledge request", "error", err)
+		return
+	}
+	defer ensureFollowup(s, i)
+
+	if videoID == "" {
+		slog.Error("youtube button is missing a video ID", "custom_id", i.MessageComponentData().CustomID)
+		return
+	}
+	slog.Info("received youtube request", "user", i.Member.User.Username, "action", name, "video_id", videoID)
+
+	actions := map[string]func(){
+		"yt_download_video": func() { h.sendVideo(s, i, videoID, youtube.DownloadOptions{Media: youtube.MediaVideo}) },
+		"yt_download_audio": func() { h.sendVideo(s, i, videoID, youtube.DownloadOptions{Media: youtube.MediaAudio}) },
+		"yt_info":           func() { h.sendVideoInfo(s, i, videoID) },
+	}
+	action, ok := actions[name]
+	if !ok {
+		writeMessage(s, i, "Unknown request action")
+		return
+	}
+	action()
+}
+
+func (h *Handlers) sendVideo(s *discordgo.Session, i *discordgo.InteractionCreate, videoID string, opts youtube.DownloadOptions) {
+	err := h.deliverVideo(s, i, videoID, opts)
+	if msg, ok := getDownloadErrorMessage(err); ok {
+		writeResponse(s, i, withMessage("Unable to download video, %s.", msg))
+		return
+	}
+	if err != nil {
+		slog.Error("failed to send video", "video_id", videoID, "error", err)
+	}
+}
+
+// deliverVideo downloads the video and uploads it, or hosts it when it's too large to upload
+func (h *Handlers) deliverVideo(s *discordgo.Session, i *discordgo.InteractionCreate, videoID string, opts youtube.DownloadOptions) error {
+	// don't fetch a stream discord won't let us upload, unless we can host it ourselves
+	uploadLimit := getUploadLimit(s, i.GuildID)
+	opts.MaxSize = uploadLimit
+	if h.fileHost != nil {
+		opts.MaxSize = max(uploadLimit, h.fileHost.MaxFileSize())
+	}
+	video, err := h.ytClient.Download(videoID, opts)
+	if err != nil {
+		return err
+	}
+	slog.Info("downloading video", "video_id", videoID, "name", video.Name, "quality", video.Quality,
+		"size", video.Size, "cached", video.Cached)
+
+	if h.fileHost != nil && (video.Size == 0 || video.Size > uploadLimit) {
+		return h.sendHostedFile(s, i, video)
+	}
+
 	files := []*discordgo.File{mapFile(video)}
+	if video.Downgraded {
+		writeResponse(s, i, withFiles(files),
+			withMessage("The requested quality is too large to upload here, sent %s instead.", video.Quality))
+		return nil
+	}
 	writeResponse(s, i, withFiles(files))
+	return nil
+}
+
+// sendHostedFile stores a file too large to upload on the file host and replies with a link to it
+func (h *Handlers) sendHostedFile(s *discordgo.Session, i *discordgo.InteractionCreate, file *youtube.File) error {
+	defer file.ReaderCloser.Close()
+	link, expires, err := h.fileHost.Store(file.Name, file.ReaderCloser)
+	if err != nil {
+		return fmt.Errorf("host file: %w", err)
+	}
+	writeResponse(s, i, withMessage("This %s download is too large to upload here, grab it from [%s](%s). The link expires <t:%d:R>.",
+		file.Quality, file.Name, link, expires.Unix()))
+	return nil
+}
+
+func (h *Handlers) sendVideoInfo(s *discordgo.Session, i *discordgo.InteractionCreate, videoID string) {
+	info, err := h.ytClient.GetVideo(videoID)
+	if err != nil {
+		slog.Error("failed to get video info", "video_id", videoID, "error", err)
+		return
+	}
+	writeResponse(s, i, withEmbeds([]*discordgo.MessageEmbed{mapYTVideoInfo(info)}))
+}
+
+// maxPlaylistDownloads is the most videos downloaded from a playlist in a single request
+const maxPlaylistDownloads = 10
+
+// playlistQueueWorkers is how many playlist entries are downloaded at once in queue mode
+const playlistQueueWorkers = 3
+
+// playlistQueueWindow is how long after the interaction queued entries are still started. Interaction tokens last
+// 15 minutes, the rest is left for the last downloads to finish
+const playlistQueueWindow = 12 * time.Minute
+
+// zipEntryOverhead is roughly how many bytes zip headers add to each entry
+const zipEntryOverhead = 1 << 10
+
+func (h *Handlers) Playlist(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	opts := NewRequestOptions(i.ApplicationCommandData().Options)
+	subcommand, subOpts := opts.GetSubcommand()
+
+	subcommands := map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions){
+		"info":     h.playlistInfo,
+		"download": h.playlistDownload,
+	}
+	handler, ok := subcommands[subcommand]
+	if !ok {
+		writeMessage(s, i, "Unknown playlist command")
+		return
+	}
+	handler(s, i, subOpts)
+}
+
+func (h *Handlers) playlistInfo(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions) {
+	playlist, ok := h.getPlaylist(s, i, opts)
+	if !ok {
+		return
+	}
+	writeResponse(s, i, withEmbeds([]*discordgo.MessageEmbed{mapYTPlaylist(playlist)}))
+}
+
+func (h *Handlers) playlistDownload(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions) {
+	playlist, ok := h.getPlaylist(s, i, opts)
+	if !ok {
+		return
+	}
+
+	selection, _ := opts.GetString("entries")
+	entries, err := playlist.Select(selection)
+	if err != nil {
+		writeResponse(s, i, withMessage("Unable to download playlist, %s.", err.Error()))
+		return
+	}
+	if len(entries) > maxPlaylistDownloads {
+		if selection != "" {
+			writeResponse(s, i, withMessage("Only %d videos can be downloaded at a time.", maxPlaylistDownloads))
+			return
+		}
+		entries = entries[:maxPlaylistDownloads]
+	}
+
+	format, _ := opts.GetString("format")
+	delivery, _ := opts.GetString("delivery")
+	dlOpts := youtube.DownloadOptions{Media: youtube.Media(format)}
+	if delivery == "queue" {
+		h.sendPlaylistQueue(s, i, playlist, entries, dlOpts)
+		return
+	}
+	h.sendPlaylistZip(s, i, playlist, entries, dlOpts)
+}
+
+// sendPlaylistQueue sends each entry as its own followup, a few at a time so the queue finishes while the
+// interaction can still be followed up on. Ends with a summary of the entries that couldn't be sent
+func (h *Handlers) sendPlaylistQueue(s *discordgo.Session, i *discordgo.InteractionCreate, playlist *youtube.Playlist, entries []youtube.PlaylistEntry, opts youtube.DownloadOptions) {
+	writeResponse(s, i, withMessage("Downloading %d videos from %s.", len(entries), playlist.Title))
+
+	deadline := time.Now().Add(playlistQueueWindow)
+	if created, err := discordgo.SnowflakeTimestamp(i.ID); err == nil {
+		deadline = created.Add(playlistQueueWindow)
+	}
+
+	failures := make([]string, len(entries))
+	entryCh := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < playlistQueueWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range entryCh {
+				entry := entries[n]
+				if time.Now().After(deadline) {
+					failures[n] = fmt.Sprintf("%s (ran out of time)", entry.Title)
+					continue
+				}
+				err := h.deliverVideo(s, i, entry.ID, opts)
+				if err == nil {
+					continue
+				}
+				reason, ok := getDownloadErrorMessage(err)
+				if !ok {
+					slog.Error("failed to send playlist entry", "video_id", entry.ID, "error", err)
+					reason = "something went wrong"
+				}
+				failures[n] = fmt.Sprintf("%s (%s)", entry.Title, reason)
+			}
+		}()
+	}
+	for n := range entries {
+		entryCh <- n
+	}
+	close(entryCh)
+	wg.Wait()
+
+	failed := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		if failure != "" {
+			failed = append(failed, failure)
+		}
+	}
+	if len(failed) == 0 {
+		writeResponse(s, i, withMessage("Finished downloading %d videos from %s.", len(entries), playlist.Title))
+		return
+	}
+	writeResponse(s, i, withMessage("Sent %d of %d videos from %s. Couldn't send: %s", len(entries)-len(failed),
+		len(entries), playlist.Title, joinLimited(failed, ", ", maxMessageContent/2)))
+}
+
+// sendPlaylistZip downloads the entries into a single zip. Entries that would push the zip over the upload limit
+// are left out
+func (h *Handlers) sendPlaylistZip(s *discordgo.Session, i *discordgo.InteractionCreate, playlist *youtube.Playlist, entries []youtube.PlaylistEntry, opts youtube.DownloadOptions) {
+	limit := getUploadLimit(s, i.GuildID)
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	skipped := make([]string, 0)
+	for n, entry := range entries {
+		remaining := limit - int64(buf.Len()) - int64(len(entries)-n)*zipEntryOverhead
+		if remaining <= 0 {
+			skipped = append(skipped, entry.Title)
+			continue
+		}
+		if err := addZipEntry(zw, h.ytClient, entry, n+1, opts, remaining); err != nil {
+			slog.Warn("skipping playlist entry", "video_id", entry.ID, "error", err)
+			skipped = append(skipped, entry.Title)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		slog.Error("failed to write playlist zip", "playlist_id", playlist.ID, "error", err)
+		return
+	}
+	if len(skipped) == len(entries) {
+		writeMessage(s, i, "Unable to download playlist, none of the videos fit within the upload limit.")
+		return
+	}
+
+	files := []*discordgo.File{{
+		Name:        strings.Join(strings.Split(playlist.Title, " "), "-") + ".zip",
+		ContentType: "application/zip",
+		Reader:      buf,
+	}}
+	if len(skipped) != 0 {
+		writeResponse(s, i, withFiles(files), withMessage("Left out videos that didn't fit within the upload limit: %s",
+			strings.Join(skipped, ", ")))
+		return
+	}
+	writeResponse(s, i, withFiles(files))
+}
+
+func (h *Handlers) getPlaylist(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions) (*youtube.Playlist, bool) {
+	playlistURL, _ := opts.GetString("url")
+	playlist, err := h.ytClient.GetPlaylist(playlistURL)
+	if errors.Is(err, youtube.ErrInvalidPlaylist) {
+		writeMessage(s, i, "That isn't a valid YouTube playlist.")
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("failed to get playlist", "url", playlistURL, "error", err)
+		return nil, false
+	}
+	if len(playlist.Entries) == 0 {
+		writeMessage(s, i, "That playlist is empty.")
+		return nil, false
+	}
+	return playlist, true
 }
 
 func (h *Handlers) Bedtime(s *discordgo.Session, i *discordgo.InteractionCreate) {
@@ -114,8 +533,58 @@ func (h *Handlers) CoinFlip(s *discordgo.Session, i *discordgo.InteractionCreate
 
 func (h *Handlers) TalkingStick(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	opts := NewRequestOptions(i.ApplicationCommandData().Options)
-	turnDuration := opts.GetIntDefault("duration", 15)
-	duration := time.Duration(turnDuration) * time.Second
+	subcommand, subOpts := opts.GetSubcommand()
+
+	subcommands := map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions){
+		"start":    h.talkingStickStart,
+		"stats":    h.talkingStickStats,
+		"stop":     h.talkingStickAction(talkingstick.ActionQuitSession, "Ending talking stick session"),
+		"skip":     h.talkingStickAction(talkingstick.ActionSkipUser, "Skipping the current speaker"),
+		"pause":    h.talkingStickAction(talkingstick.ActionPause, "Paused the talking stick session"),
+		"resume":   h.talkingStickAction(talkingstick.ActionResume, "Resumed the talking stick session"),
+		"panel":    h.talkingStickAction(talkingstick.ActionRepostPanel, "Reposted the control panel"),
+		"status":   h.talkingStickAction(talkingstick.ActionStatus, ""),
+		"order":    h.talkingStickAction(talkingstick.ActionOrder, ""),
+		"settings": h.talkingStickSettings,
+	}
+	handler, ok := subcommands[subcommand]
+	if !ok {
+		writeMessage(s, i, "Unknown talking stick command")
+		return
+	}
+	handler(s, i, subOpts)
+}
+
+func (h *Handlers) talkingStickStart(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions) {
+	// start with the guild's defaults and override them with anything that was requested
+	settings, err := h.tsManager.Settings(i.GuildID)
+	if err != nil {
+		slog.Error("failed to get talking stick settings, using defaults", "guild_id", i.GuildID, "error", err)
+	}
+	cfg := settings.SessionConfig()
+	if turnDuration, ok := opts.GetInt("duration"); ok {
+		cfg.TurnDuration = time.Duration(turnDuration) * time.Second
+	}
+	cfg.Strict = opts.GetBoolDefault("strict", cfg.Strict)
+	cfg.Skip = talkingstick.SkipRules{
+		SelfMuted:    opts.GetBoolDefault("skip-muted", false),
+		SelfDeafened: opts.GetBoolDefault("skip-deafened", false),
+		Away:         opts.GetBoolDefault("skip-away", false),
+	}
+	cfg.Debate = talkingstick.DebateConfig{
+		Opening:  time.Duration(opts.GetIntDefault("opening", 0)) * time.Second,
+		Rebuttal: time.Duration(opts.GetIntDefault("rebuttal", 0)) * time.Second,
+		Closing:  time.Duration(opts.GetIntDefault("closing", 0)) * time.Second,
+	}
+	if moderator, ok := opts.GetUserByName(s, "moderator"); ok {
+		cfg.Debate.ModeratorID = moderator.ID
+	}
+	if mode, ok := opts.GetString("mode"); ok {
+		cfg.Mode = talkingstick.Mode(mode)
+	}
+	if order, ok := opts.GetString("order"); ok {
+		cfg.Order = talkingstick.OrderStrategy(order)
+	}
 
 	// get the users active voice channel
 	vs, err := getVoiceState(s, i.GuildID, i.Member.User.ID)
@@ -124,30 +593,127 @@ func (h *Handlers) TalkingStick(s *discordgo.Session, i *discordgo.InteractionCr
 		return
 	}
 
-	if err = h.tsManager.Create(vs.GuildID, vs.ChannelID, duration); err != nil {
+	if err = h.tsManager.Create(vs.GuildID, vs.ChannelID, i.Member.User.ID, cfg); err != nil {
 		if errors.Is(err, talkingstick.ErrSessionExists) {
 			writeMessage(s, i, "A talking stick already exists in your current voice channel")
 			return
 		}
+		if errors.Is(err, talkingstick.ErrNotEnoughDebaters) {
+			writeMessage(s, i, "A debate needs at least two debaters in your voice channel")
+			return
+		}
 		slog.Error("failed to create talking stick session", "channel_id", vs.ChannelID, "error", err)
 		return
 	}
 	writeMessage(s, i, "Initiating talking stick session")
 }
 
-func (h *Handlers) getVideoIDFromRequest(opts RequestOptions) (string, error) {
-	videoID, ok := opts.GetString("url")
-	if ok {
-		return videoID, nil
+// talkingStickAction performs the action on the session in the members current voice channel
+func (h *Handlers) talkingStickAction(action talkingstick.Action, msg string) func(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate, _ RequestOptions) {
+		vs, err := getVoiceState(s, i.GuildID, i.Member.User.ID)
+		if err != nil {
+			writeMessage(s, i, "Failed to get voice state. Are you in a voice channel?")
+			return
+		}
+
+		req := talkingstick.ActionRequest{ChannelID: vs.ChannelID, Action: action, Member: i.Member}
+		embed, err := h.tsManager.Handle(req)
+		if err != nil {
+			if msg, ok := getTSErrorMessage(err); ok {
+				writeMessage(s, i, msg)
+				return
+			}
+			slog.Error("failed to perform action", "channel_id", vs.ChannelID, "action", action, "error", err)
+			return
+		}
+
+		if embed != nil {
+			writeResponse(s, i, withEmbeds([]*discordgo.MessageEmbed{embed}))
+			return
+		}
+		writeMessage(s, i, msg)
+	}
+}
+
+func (h *Handlers) talkingStickStats(s *discordgo.Session, i *discordgo.InteractionCreate, _ RequestOptions) {
+	stats, err := h.tsManager.Stats(i.GuildID)
+	if err != nil {
+		slog.Error("failed to get talking stick stats", "guild_id", i.GuildID, "error", err)
+		return
+	}
+	embed := talkingstick.StatsEmbed("All-Time Talking Stick Stats", stats)
+	writeResponse(s, i, withEmbeds([]*discordgo.MessageEmbed{embed}))
+}
+
+func (h *Handlers) talkingStickSettings(s *discordgo.Session, i *discordgo.InteractionCreate, opts RequestOptions) {
+	settings, err := h.tsManager.Settings(i.GuildID)
+	if err != nil {
+		slog.Error("failed to get talking stick settings", "guild_id", i.GuildID, "error", err)
+		return
+	}
+
+	// no options means the user just wants to see the current settings
+	if len(opts) != 0 {
+		if i.Member.Permissions&discordgo.PermissionManageServer == 0 {
+			writeMessage(s, i, "You need the Manage Server permission to change talking stick settings.")
+			return
+		}
+		if duration, ok := opts.GetInt("duration"); ok {
+			settings.TurnDuration = time.Duration(duration) * time.Second
+		}
+		if timeout, ok := opts.GetInt("inactivity-timeout"); ok {
+			settings.InactivityTimeout = time.Duration(timeout) * time.Minute
+		}
+		if order, ok := opts.GetString("order"); ok {
+			settings.Order = talkingstick.OrderStrategy(order)
+		}
+		if strict, ok := opts.GetBool("strict"); ok {
+			settings.Strict = strict
+		}
+		if channel, ok := opts.GetChannel(s, "panel-channel"); ok {
+			settings.PanelChannelID = channel.ID
+		}
+		if opts.GetBoolDefault("reset-panel-channel", false) {
+			settings.PanelChannelID = ""
+		}
+		if settings.TurnDuration <= 0 || settings.InactivityTimeout <= 0 {
+			writeMessage(s, i, "Durations must be greater than zero.")
+			return
+		}
+		requested := settings.TurnDuration
+		if settings, err = h.tsManager.UpdateSettings(i.GuildID, settings); err != nil {
+			slog.Error("failed to update talking stick settings", "guild_id", i.GuildID, "error", err)
+			return
+		}
+		if settings.TurnDuration != requested {
+			embed := mapTSSettings(settings)
+			writeResponse(s, i, withEmbeds([]*discordgo.MessageEmbed{embed}),
+				withMessage("A turn duration of %s is out of range, using %s instead.", requested, settings.TurnDuration))
+			return
+		}
+	}
+
+	embed := mapTSSettings(settings)
+	writeResponse(s, i, withEmbeds([]*discordgo.MessageEmbed{embed}))
+}
+
+// getVideoFromRequest resolves the video from the url option, or the top search result for the query option
+func (h *Handlers) getVideoFromRequest(opts RequestOptions) (media.Reference, error) {
+	if rawURL, ok := opts.GetString("url"); ok {
+		return h.resolver.Resolve(rawURL)
 	}
 
 	query, _ := opts.GetString("query")
-	result, err := h.ytClient.Search(query)
+	result, err := h.ytClient.Search(youtube.SearchRequest{Query: query, Type: youtube.SearchVideo, MaxResults: 1})
+	if errors.Is(err, youtube.ErrQuotaExhausted) {
+		return media.Reference{}, err
+	}
 	if err != nil {
-		return "", fmt.Errorf("search error: %w", err)
+		return media.Reference{}, fmt.Errorf("search error: %w", err)
 	}
 	if len(result.Items) == 0 {
-		return "", fmt.Errorf("no search results found for query: %s", query)
+		return media.Reference{}, fmt.Errorf("no search results found for query: %s", query)
 	}
-	return result.Items[0].ID.VideoID, nil
+	return media.Reference{Site: media.SiteYouTube, ID: result.Items[0].ID.VideoID}, nil
 }
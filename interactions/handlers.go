@@ -1,10 +1,14 @@
 package interactions
 
 import (
+	"github.com/Zach51920/discord-bot/filehost"
+	"github.com/Zach51920/discord-bot/media"
 	"github.com/Zach51920/discord-bot/talkingstick"
 	"github.com/Zach51920/discord-bot/youtube"
 	"github.com/bwmarrin/discordgo"
+	"github.com/jmoiron/sqlx"
 	"log/slog"
+	"strings"
 	"sync"
 )
 
@@ -13,16 +17,22 @@ type Handlers struct {
 	mu sync.Mutex
 
 	ytClient   *youtube.Client
+	resolver   *media.Chain
+	searches   *searchStore
 	tsManager  talkingstick.SessionManager
+	fileHost   *filehost.Host // nil when file hosting is disabled
 	shutdownCh chan struct{}
 }
 
-func New(s *discordgo.Session) *Handlers {
+func New(s *discordgo.Session, db *sqlx.DB, ytClient *youtube.Client, fileHost *filehost.Host) *Handlers {
 	return &Handlers{
-		ytClient:   youtube.New(),
+		ytClient:   ytClient,
+		resolver:   media.NewChain(media.YouTube{}),
+		searches:   newSearchStore(),
 		wg:         sync.WaitGroup{},
 		shutdownCh: make(chan struct{}),
-		tsManager:  talkingstick.NewSessionManager(s),
+		tsManager:  talkingstick.NewSessionManager(s, db),
+		fileHost:   fileHost,
 	}
 }
 
@@ -46,6 +56,9 @@ func (h *Handlers) HandleCommand(s *discordgo.Session, i *discordgo.InteractionC
 	commands := map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
 		"yt-download":   h.Download,
 		"yt-search":     h.Search,
+		"yt-playlist":   h.Playlist,
+		"yt-info":       h.Info,
+		"yt-transcript": h.Transcript,
 		"bedtime-ban":   h.Bedtime,
 		"talking-stick": h.TalkingStick,
 		"coinflip":      h.CoinFlip,
@@ -69,6 +82,21 @@ func (h *Handlers) HandleButtons(s *discordgo.Session, i *discordgo.InteractionC
 	customID := i.MessageComponentData().CustomID
 	slog.Info("received button press event", "custom_id", customID)
 
+	// components are scoped with state after the name, ie. the session's channel ID or a video ID
+	name, state, _ := strings.Cut(customID, ":")
+	switch {
+	case strings.HasPrefix(name, "talking_stick_"):
+		h.talkingStickButton(s, i, name, state)
+	case name == "yt_search_page":
+		h.searchPageButton(s, i, state)
+	case strings.HasPrefix(name, "yt_"):
+		h.youtubeButton(s, i, name, state)
+	default:
+		slog.Error("unknown request action", "custom_id", customID)
+	}
+}
+
+func (h *Handlers) talkingStickButton(s *discordgo.Session, i *discordgo.InteractionCreate, name, channelID string) {
 	// acknowledge the request
 	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredMessageUpdate}); err != nil {
@@ -80,27 +108,50 @@ func (h *Handlers) HandleButtons(s *discordgo.Session, i *discordgo.InteractionC
 		"talking_stick_playpause": talkingstick.ActionTogglePlayPause,
 		"talking_stick_next":      talkingstick.ActionSkipUser,
 		"talking_stick_quit":      talkingstick.ActionQuitSession,
+		"talking_stick_raise":     talkingstick.ActionRaiseHand,
+		"talking_stick_lower":     talkingstick.ActionLowerHand,
+		"talking_stick_bump":      talkingstick.ActionBumpHand,
+		"talking_stick_time_up":   talkingstick.ActionIncreaseTurn,
+		"talking_stick_time_down": talkingstick.ActionDecreaseTurn,
+		"talking_stick_extend":    talkingstick.ActionExtendTurn,
 	}
-	action, ok := actions[customID]
+	action, ok := actions[name]
 	if !ok {
-		slog.Error("unknown request action", "custom_id", customID)
+		slog.Error("unknown request action", "custom_id", i.MessageComponentData().CustomID)
 		return
 	}
 
-	// get voice channel
-	vs, err := getVoiceState(s, i.GuildID, i.Member.User.ID)
-	if err != nil {
-		writeMessage(s, i, "Failed to get voice state. Are you in a voice channel?")
-		return
+	// panels created before components were scoped fall back to the members voice channel
+	if channelID == "" {
+		vs, err := getVoiceState(s, i.GuildID, i.Member.User.ID)
+		if err != nil {
+			writeResponse(s, i, withMessage("Failed to get voice state. Are you in a voice channel?"), withEphemeral())
+			return
+		}
+		channelID = vs.ChannelID
 	}
 
 	// perform the action
-	if err = h.tsManager.Handle(vs.ChannelID, action); err != nil {
-		slog.Error("failed to perform action", "channel_id", vs.ChannelID, "error", err)
+	req := talkingstick.ActionRequest{
+		ChannelID: channelID,
+		Action:    action,
+		Member:    i.Member,
+		Values:    i.MessageComponentData().Values,
+	}
+	if _, err := h.tsManager.Handle(req); err != nil {
+		if msg, ok := getTSErrorMessage(err); ok {
+			writeResponse(s, i, withMessage(msg), withEphemeral())
+			return
+		}
+		slog.Error("failed to perform action", "channel_id", channelID, "error", err)
 		return
 	}
 }
 
+func (h *Handlers) HandleVoiceStateUpdate(s *discordgo.Session, e *discordgo.VoiceStateUpdate) {
+	h.tsManager.HandleVoiceStateUpdate(s, e)
+}
+
 func (h *Handlers) Close() error {
 	h.wg.Wait()
 	close(h.shutdownCh)
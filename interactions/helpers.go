@@ -1,12 +1,24 @@
 package interactions
 
 import (
+	"archive/zip"
+	"errors"
 	"fmt"
+	"github.com/Zach51920/discord-bot/filehost"
+	"github.com/Zach51920/discord-bot/media"
+	"github.com/Zach51920/discord-bot/talkingstick"
 	"github.com/Zach51920/discord-bot/youtube"
 	"github.com/bwmarrin/discordgo"
+	"io"
 	"log/slog"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// maxActionRows is the most rows of components discord allows on a single message
+const maxActionRows = 5
+
 func logRequest(i *discordgo.InteractionCreate) {
 	data := i.ApplicationCommandData()
 	args := make([]any, 4, 4+(len(data.Options)*2))
@@ -21,25 +33,273 @@ func logRequest(i *discordgo.InteractionCreate) {
 func mapYTSearchResults(results youtube.YTSearchResults) []*discordgo.MessageEmbed {
 	embeds := make([]*discordgo.MessageEmbed, len(results.Items))
 	for i, item := range results.Items {
+		idField := &discordgo.MessageEmbedField{Name: "Video ID", Value: item.ID.VideoID}
+		if item.ID.PlaylistID != "" {
+			idField = &discordgo.MessageEmbedField{Name: "Playlist ID", Value: item.ID.PlaylistID}
+		} else if item.ID.VideoID == "" && item.ID.ChannelID != "" {
+			idField = &discordgo.MessageEmbedField{Name: "Channel", Value: item.Snippet.ChannelTitle}
+		}
 		embeds[i] = &discordgo.MessageEmbed{
-			Title:       item.Snippet.Title,
+			Title:       fmt.Sprintf("%d. %s", i+1, item.Snippet.Title),
 			Description: item.Snippet.Description,
-			URL:         getYouTubeURL(item.ID.VideoID),
+			URL:         getYTSearchItemURL(item),
 			Thumbnail: &discordgo.MessageEmbedThumbnail{
 				URL:    item.Snippet.Thumbnails.Default.URL,
 				Width:  item.Snippet.Thumbnails.Default.Width,
 				Height: item.Snippet.Thumbnails.Default.Height,
 			},
-			Fields: []*discordgo.MessageEmbedField{
-				{Name: "Video ID", Value: item.ID.VideoID},
-			},
+			Fields: []*discordgo.MessageEmbedField{idField},
+		}
+	}
+	if len(embeds) != 0 && results.PageInfo.TotalResults != 0 {
+		embeds[len(embeds)-1].Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("About %d results", results.PageInfo.TotalResults),
 		}
 	}
 	return embeds
 }
 
-func getYouTubeURL(videoID string) string {
-	return fmt.Sprintf("https://www.youtube.com/watch?v=%s", videoID)
+func getYTSearchItemURL(item youtube.YTSearchItem) string {
+	switch {
+	case item.ID.VideoID != "":
+		return youtube.WatchURL(item.ID.VideoID)
+	case item.ID.PlaylistID != "":
+		return "https://www.youtube.com/playlist?list=" + item.ID.PlaylistID
+	default:
+		return "https://www.youtube.com/channel/" + item.ID.ChannelID
+	}
+}
+
+// mapYTSearchComponents adds a row of buttons for each search result, numbered to match the result embeds, and
+// the Next/Prev buttons when there are more pages
+func mapYTSearchComponents(results youtube.YTSearchResults, searchID string) []discordgo.MessageComponent {
+	paged := results.NextPageToken != "" || results.PrevPageToken != ""
+	maxRows := maxActionRows
+	if paged {
+		maxRows-- // save a row for the page buttons
+	}
+
+	components := make([]discordgo.MessageComponent, 0, len(results.Items)+1)
+	for i, item := range results.Items {
+		if item.ID.VideoID == "" {
+			continue // channels and playlists can't be downloaded
+		}
+		if len(components) == maxRows {
+			break
+		}
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    fmt.Sprintf("#%d Download video", i+1),
+					Style:    discordgo.PrimaryButton,
+					CustomID: getYTCustomID("yt_download_video", item.ID.VideoID),
+				},
+				discordgo.Button{
+					Label:    fmt.Sprintf("#%d Download audio", i+1),
+					Style:    discordgo.SecondaryButton,
+					CustomID: getYTCustomID("yt_download_audio", item.ID.VideoID),
+				},
+				discordgo.Button{
+					Label:    fmt.Sprintf("#%d Info", i+1),
+					Style:    discordgo.SecondaryButton,
+					CustomID: getYTCustomID("yt_info", item.ID.VideoID),
+				},
+			},
+		})
+	}
+
+	if paged {
+		pageButtons := make([]discordgo.MessageComponent, 0, 2)
+		if results.PrevPageToken != "" {
+			pageButtons = append(pageButtons, discordgo.Button{
+				Label:    "Prev",
+				Style:    discordgo.SecondaryButton,
+				CustomID: getYTCustomID("yt_search_page", searchID+":"+results.PrevPageToken),
+			})
+		}
+		if results.NextPageToken != "" {
+			pageButtons = append(pageButtons, discordgo.Button{
+				Label:    "Next",
+				Style:    discordgo.SecondaryButton,
+				CustomID: getYTCustomID("yt_search_page", searchID+":"+results.NextPageToken),
+			})
+		}
+		components = append(components, discordgo.ActionsRow{Components: pageButtons})
+	}
+	return components
+}
+
+// maxFieldValue is the longest value discord allows in an embed field
+const maxFieldValue = 1024
+
+func mapYTVideoInfo(info *youtube.VideoInfo) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: info.Title,
+		URL:   youtube.WatchURL(info.ID),
+		Color: 0xFF0000, // Red
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Channel", Value: info.Author, Inline: true},
+			{Name: "Duration", Value: youtube.FormatTimestamp(info.Duration), Inline: true},
+			{Name: "Views", Value: strconv.Itoa(info.Views), Inline: true},
+		},
+	}
+	if !info.PublishDate.IsZero() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: "Published", Value: fmt.Sprintf("<t:%d:D>", info.PublishDate.Unix()), Inline: true,
+		})
+	}
+
+	qualities := make([]string, len(info.Qualities))
+	for i, q := range info.Qualities {
+		size := "unknown size"
+		if q.Size > 0 {
+			size = formatBytes(q.Size)
+		}
+		qualities[i] = fmt.Sprintf("%s `%s` %s", q.Quality, q.Container, size)
+	}
+	chapters := make([]string, len(info.Chapters))
+	for i, chapter := range info.Chapters {
+		chapters[i] = fmt.Sprintf("`%s` %s", youtube.FormatTimestamp(chapter.Start), chapter.Title)
+	}
+	captions := make([]string, len(info.Captions))
+	for i, caption := range info.Captions {
+		captions[i] = caption.Name
+		if caption.Name == "" {
+			captions[i] = caption.LanguageCode
+		}
+	}
+	for _, field := range []struct {
+		name  string
+		lines []string
+		sep   string
+	}{
+		{name: "Qualities", lines: qualities, sep: "\n"},
+		{name: "Chapters", lines: chapters, sep: "\n"},
+		{name: "Captions", lines: captions, sep: ", "},
+	} {
+		if len(field.lines) != 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name: field.name, Value: joinLimited(field.lines, field.sep, maxFieldValue), Inline: false,
+			})
+		}
+	}
+
+	if info.ThumbnailURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: info.ThumbnailURL}
+	}
+	return embed
+}
+
+// joinLimited joins as many of the elements as fit within limit characters, noting how many were left out
+func joinLimited(elems []string, sep string, limit int) string {
+	var b strings.Builder
+	for i, elem := range elems {
+		more := fmt.Sprintf("...and %d more", len(elems)-i)
+		if b.Len()+len(elem)+len(sep)+len(more) > limit {
+			b.WriteString(more)
+			break
+		}
+		if i != 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(elem)
+	}
+	return b.String()
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	default:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+}
+
+// maxMessageContent is the longest message content discord allows
+const maxMessageContent = 2000
+
+// maxEmbedDescription is the longest description discord allows on an embed
+const maxEmbedDescription = 4096
+
+func mapYTPlaylist(playlist *youtube.Playlist) *discordgo.MessageEmbed {
+	var description strings.Builder
+	for n, entry := range playlist.Entries {
+		line := fmt.Sprintf("%d. [%s](%s) `%s`\n", n+1, entry.Title, youtube.WatchURL(entry.ID), youtube.FormatTimestamp(entry.Duration))
+		more := fmt.Sprintf("...and %d more", len(playlist.Entries)-n)
+		if description.Len()+len(line)+len(more) > maxEmbedDescription {
+			description.WriteString(more)
+			break
+		}
+		description.WriteString(line)
+	}
+
+	var total time.Duration
+	for _, entry := range playlist.Entries {
+		total += entry.Duration
+	}
+	return &discordgo.MessageEmbed{
+		Title:       playlist.Title,
+		URL:         "https://www.youtube.com/playlist?list=" + playlist.ID,
+		Description: description.String(),
+		Color:       0xFF0000, // Red
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Channel", Value: playlist.Author, Inline: true},
+			{Name: "Videos", Value: strconv.Itoa(len(playlist.Entries)), Inline: true},
+			{Name: "Total Duration", Value: youtube.FormatTimestamp(total), Inline: true},
+		},
+	}
+}
+
+// addZipEntry downloads the playlist entry into the zip, as long as it is no larger than maxSize
+func addZipEntry(zw *zip.Writer, client *youtube.Client, entry youtube.PlaylistEntry, n int, opts youtube.DownloadOptions, maxSize int64) error {
+	opts.MaxSize = maxSize
+	video, err := client.Download(entry.ID, opts)
+	if err != nil {
+		return err
+	}
+	defer video.ReaderCloser.Close()
+
+	// the size is only an estimate, read it all before committing it to the zip
+	data, err := io.ReadAll(io.LimitReader(video.ReaderCloser, maxSize+1))
+	if err != nil {
+		return fmt.Errorf("read video: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return youtube.ErrTooLarge
+	}
+
+	// videos are already compressed, storing them keeps the zip size predictable
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: fmt.Sprintf("%02d-%s", n, video.Name), Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("create zip entry: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// getYTCustomID scopes a youtube component to a video
+func getYTCustomID(name, videoID string) string {
+	return name + ":" + videoID
+}
+
+// getClip reads the start and end timestamps of the requested clip
+func getClip(opts RequestOptions) (youtube.Clip, error) {
+	var clip youtube.Clip
+	var err error
+	if start, ok := opts.GetString("start"); ok {
+		if clip.Start, err = youtube.ParseTimestamp(start); err != nil {
+			return clip, err
+		}
+	}
+	if end, ok := opts.GetString("end"); ok {
+		if clip.End, err = youtube.ParseTimestamp(end); err != nil {
+			return clip, err
+		}
+	}
+	return clip, nil
 }
 
 func mapFile(f *youtube.File) *discordgo.File {
@@ -53,6 +313,27 @@ func mapFile(f *youtube.File) *discordgo.File {
 	}
 }
 
+// uploadLimits are the largest files that can be uploaded to a guild for each boost tier
+var uploadLimits = map[discordgo.PremiumTier]int64{
+	discordgo.PremiumTierNone: 10 << 20,
+	discordgo.PremiumTier1:    10 << 20,
+	discordgo.PremiumTier2:    50 << 20,
+	discordgo.PremiumTier3:    100 << 20,
+}
+
+// getUploadLimit returns the largest file in bytes that can be uploaded to the guild
+func getUploadLimit(s *discordgo.Session, guildID string) int64 {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		slog.Warn("failed to get guild, using the default upload limit", "guild_id", guildID, "error", err)
+		return uploadLimits[discordgo.PremiumTierNone]
+	}
+	if limit, ok := uploadLimits[guild.PremiumTier]; ok {
+		return limit
+	}
+	return uploadLimits[discordgo.PremiumTierNone]
+}
+
 func getVoiceState(s *discordgo.Session, guildID, userID string) (*discordgo.VoiceState, error) {
 	vs, err := s.State.VoiceState(guildID, userID)
 	if err != nil {
@@ -64,3 +345,69 @@ func getVoiceState(s *discordgo.Session, guildID, userID string) (*discordgo.Voi
 	}
 	return vs, nil
 }
+
+func mapTSSettings(settings talkingstick.GuildSettings) *discordgo.MessageEmbed {
+	panelChannel := "Voice channel chat"
+	if settings.PanelChannelID != "" {
+		panelChannel = fmt.Sprintf("<#%s>", settings.PanelChannelID)
+	}
+	return &discordgo.MessageEmbed{
+		Title: "Talking Stick Settings",
+		Color: 0x0000FF, // Blue
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Turn Duration", Value: settings.TurnDuration.String(), Inline: true},
+			{Name: "Inactivity Timeout", Value: settings.InactivityTimeout.String(), Inline: true},
+			{Name: "Speaking Order", Value: string(settings.Order), Inline: false},
+			{Name: "Strict Mode", Value: strconv.FormatBool(settings.Strict), Inline: true},
+			{Name: "Panel Channel", Value: panelChannel, Inline: true},
+		},
+	}
+}
+
+// getVideoRequestMessage maps errors finding the requested video the user can do something about to a message
+func getVideoRequestMessage(err error) (string, bool) {
+	switch {
+	case errors.Is(err, youtube.ErrQuotaExhausted):
+		return "YouTube search has used up its quota for today, use a video URL instead", true
+	case errors.Is(err, media.ErrUnsupportedURL), errors.Is(err, media.ErrInvalidVideoID):
+		return err.Error(), true
+	}
+	return "", false
+}
+
+// getTSErrorMessage maps talking stick errors the user can do something about to a message they can read
+// getDownloadErrorMessage explains why a video couldn't be downloaded, ok is false if the error isn't the user's to fix
+func getDownloadErrorMessage(err error) (string, bool) {
+	switch {
+	case err == nil:
+		return "", false
+	case errors.Is(err, filehost.ErrTooLarge):
+		return "it is too large to upload or host", true
+	case errors.Is(err, youtube.ErrQualityUnavailable) || errors.Is(err, youtube.ErrTooLarge) ||
+		errors.Is(err, youtube.ErrInvalidClip) || errors.Is(err, youtube.ErrClippingUnavailable):
+		return err.Error(), true
+	}
+	return "", false
+}
+
+func getTSErrorMessage(err error) (string, bool) {
+	messages := map[error]string{
+		talkingstick.ErrSessionNotFound:    "This talking stick session is no longer active.",
+		talkingstick.ErrNotAuthorized:      "Only participants, the session creator, and moderators can control this talking stick session.",
+		talkingstick.ErrNotModerator:       "Only moderators can do that.",
+		talkingstick.ErrNotDebateModerator: "Only the debate moderator can do that.",
+		talkingstick.ErrNotStickholder:     "Only the person holding the talking stick can do that.",
+		talkingstick.ErrAlreadyExtended:    "This turn has already been extended.",
+		talkingstick.ErrAlreadyQueued:      "Your hand is already raised.",
+		talkingstick.ErrNotQueued:          "That hand isn't raised.",
+		talkingstick.ErrNotQueueMode:       "This talking stick session isn't using a raise hand queue.",
+		talkingstick.ErrAlreadyPaused:      "This talking stick session is already paused, use `/talking-stick resume` to resume it.",
+		talkingstick.ErrNotPaused:          "This talking stick session isn't paused.",
+	}
+	for target, msg := range messages {
+		if errors.Is(err, target) {
+			return msg, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package interactions
+
+import (
+	"github.com/Zach51920/discord-bot/youtube"
+	"github.com/bwmarrin/discordgo"
+	"strings"
+	"testing"
+)
+
+// customIDs collects the custom IDs of every button in the components, row by row
+func customIDs(components []discordgo.MessageComponent) [][]string {
+	rows := make([][]string, 0, len(components))
+	for _, component := range components {
+		row := make([]string, 0)
+		for _, c := range component.(discordgo.ActionsRow).Components {
+			row = append(row, c.(discordgo.Button).CustomID)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func searchResults(n int) youtube.YTSearchResults {
+	var results youtube.YTSearchResults
+	for i := 0; i < n; i++ {
+		results.Items = append(results.Items, youtube.NewVideoResult("video"+string(rune('a'+i)), "Title", "Channel"))
+	}
+	return results
+}
+
+func TestMapYTSearchComponentsPageButtons(t *testing.T) {
+	tests := []struct {
+		name     string
+		prev     string
+		next     string
+		wantPage []string
+	}{
+		{name: "first page", next: "CAQQAA", wantPage: []string{"yt_search_page:abc123:CAQQAA"}},
+		{name: "middle page", prev: "CAQQAQ", next: "CAgQAA", wantPage: []string{
+			"yt_search_page:abc123:CAQQAQ", "yt_search_page:abc123:CAgQAA"}},
+		{name: "last page", prev: "CAQQAQ", wantPage: []string{"yt_search_page:abc123:CAQQAQ"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := searchResults(maxActionRows)
+			results.PrevPageToken = tt.prev
+			results.NextPageToken = tt.next
+
+			rows := customIDs(mapYTSearchComponents(results, "abc123"))
+			if len(rows) != maxActionRows {
+				t.Fatalf("got %d rows, want %d", len(rows), maxActionRows)
+			}
+			page := rows[len(rows)-1]
+			if len(page) != len(tt.wantPage) {
+				t.Fatalf("page row = %v, want %v", page, tt.wantPage)
+			}
+			for i := range page {
+				if page[i] != tt.wantPage[i] {
+					t.Errorf("page row = %v, want %v", page, tt.wantPage)
+				}
+			}
+		})
+	}
+}
+
+func TestMapYTSearchComponentsSinglePage(t *testing.T) {
+	rows := customIDs(mapYTSearchComponents(searchResults(3), "abc123"))
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	for _, row := range rows {
+		if !strings.HasPrefix(row[0], "yt_download_video:") {
+			t.Errorf("row %v isn't a result row", row)
+		}
+	}
+}
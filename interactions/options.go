@@ -36,6 +36,21 @@ func (opts RequestOptions) GetIntDefault(key string, def int) int {
 	return def
 }
 
+func (opts RequestOptions) GetBool(key string) (bool, bool) {
+	if opt, ok := opts[key]; ok && opt.Type == discordgo.ApplicationCommandOptionBoolean {
+		return opt.Value.(bool), true
+	}
+	return false, false
+}
+
+func (opts RequestOptions) GetBoolDefault(key string, def bool) bool {
+	v, ok := opts.GetBool(key)
+	if ok {
+		return v
+	}
+	return def
+}
+
 func (opts RequestOptions) GetStringPtr(key string) *string {
 	if val, ok := opts.GetString(key); ok {
 		return &val
@@ -53,12 +68,23 @@ func (opts RequestOptions) GetSubcommand() (string, RequestOptions) {
 }
 
 func (opts RequestOptions) GetUser(s *discordgo.Session) (*discordgo.User, bool) {
-	if opt, ok := opts["user"]; ok && opt.Type == discordgo.ApplicationCommandOptionUser {
+	return opts.GetUserByName(s, "user")
+}
+
+func (opts RequestOptions) GetUserByName(s *discordgo.Session, key string) (*discordgo.User, bool) {
+	if opt, ok := opts[key]; ok && opt.Type == discordgo.ApplicationCommandOptionUser {
 		return opt.UserValue(s), true
 	}
 	return nil, false
 }
 
+func (opts RequestOptions) GetChannel(s *discordgo.Session, key string) (*discordgo.Channel, bool) {
+	if opt, ok := opts[key]; ok && opt.Type == discordgo.ApplicationCommandOptionChannel {
+		return opt.ChannelValue(s), true
+	}
+	return nil, false
+}
+
 func (opts RequestOptions) GetRole(s *discordgo.Session, i *discordgo.InteractionCreate) (*discordgo.Role, bool) {
 	if opt, ok := opts["role"]; ok && opt.Type == discordgo.ApplicationCommandOptionRole {
 		return opt.RoleValue(s, i.GuildID), true
@@ -46,12 +46,24 @@ func withFiles(files []*discordgo.File) responseParam {
 	}
 }
 
+func withComponents(components []discordgo.MessageComponent) responseParam {
+	return func(p *discordgo.WebhookParams) {
+		p.Components = components
+	}
+}
+
 func withMessage(format string, a ...any) responseParam {
 	return func(p *discordgo.WebhookParams) {
 		p.Content = fmt.Sprintf(format, a...)
 	}
 }
 
+func withEphemeral() responseParam {
+	return func(p *discordgo.WebhookParams) {
+		p.Flags |= discordgo.MessageFlagsEphemeral
+	}
+}
+
 func getRESTErrorMessage(err error) (string, bool) {
 	var restErr *discordgo.RESTError
 	if ok := errors.As(err, &restErr); !ok || restErr == nil {
@@ -0,0 +1,61 @@
+package interactions
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/Zach51920/discord-bot/youtube"
+	"sync"
+	"time"
+)
+
+// searchTTL is how long the pages of a search can be flipped through
+const searchTTL = time.Hour
+
+// searchStore remembers the searches behind the Next/Prev buttons, custom IDs are too short to hold the query and
+// filters themselves
+type searchStore struct {
+	mu       *sync.Mutex
+	searches map[string]storedSearch
+}
+
+type storedSearch struct {
+	req     youtube.SearchRequest
+	expires time.Time
+}
+
+func newSearchStore() *searchStore {
+	return &searchStore{
+		mu:       &sync.Mutex{},
+		searches: make(map[string]storedSearch),
+	}
+}
+
+// Save the search and return the ID to scope its buttons with
+func (s *searchStore) Save(req youtube.SearchRequest) string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	id := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, search := range s.searches {
+		if now.After(search.expires) {
+			delete(s.searches, key)
+		}
+	}
+	req.PageToken = ""
+	s.searches[id] = storedSearch{req: req, expires: now.Add(searchTTL)}
+	return id
+}
+
+// Get the search, ok is false if it has expired
+func (s *searchStore) Get(id string) (youtube.SearchRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	search, ok := s.searches[id]
+	if !ok || time.Now().After(search.expires) {
+		return youtube.SearchRequest{}, false
+	}
+	return search.req, true
+}
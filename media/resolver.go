@@ -0,0 +1,69 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrUnsupportedURL = errors.New("unsupported media url")
+
+// Reference is a normalized reference to a piece of media on a site
+type Reference struct {
+	// Site the media is hosted on, ie. youtube
+	Site string
+	// ID of the media on its site, empty if the URL only points to a playlist
+	ID string
+	// Start is the timestamp the URL links to, if any
+	Start time.Duration
+	// ListID is the playlist the media was linked from, if any
+	ListID string
+}
+
+// Resolver recognizes URLs that belong to a single site
+type Resolver interface {
+	// Resolve returns a reference to the media the URL points to, ok is false if the URL isn't for the resolver's site
+	Resolve(u *url.URL) (ref Reference, ok bool, err error)
+	// ResolveID returns a reference for a bare media ID, ok is false if it doesn't look like one of the site's IDs
+	ResolveID(id string) (ref Reference, ok bool)
+}
+
+// Chain tries each of its resolvers in order until one recognizes the URL
+type Chain struct {
+	resolvers []Resolver
+}
+
+func NewChain(resolvers ...Resolver) *Chain {
+	return &Chain{resolvers: resolvers}
+}
+
+// Resolve normalizes a URL or bare media ID into a reference
+func (c *Chain) Resolve(raw string) (Reference, error) {
+	raw = strings.TrimSpace(raw)
+	for _, r := range c.resolvers {
+		if ref, ok := r.ResolveID(raw); ok {
+			return ref, nil
+		}
+	}
+
+	// people often paste links without the scheme
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return Reference{}, fmt.Errorf("%w: %s", ErrUnsupportedURL, raw)
+	}
+	for _, r := range c.resolvers {
+		ref, ok, err := r.Resolve(u)
+		if err != nil {
+			return Reference{}, err
+		}
+		if ok {
+			return ref, nil
+		}
+	}
+	return Reference{}, fmt.Errorf("%w: %s", ErrUnsupportedURL, raw)
+}
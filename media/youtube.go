@@ -0,0 +1,84 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Zach51920/discord-bot/youtube"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const SiteYouTube = "youtube"
+
+var ErrInvalidVideoID = errors.New("invalid video ID")
+
+var youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// youtubeHosts are the hosts youtube serves videos from, youtu.be is handled separately
+var youtubeHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+}
+
+// youtubePathPrefixes are the paths that are followed by the video ID
+var youtubePathPrefixes = []string{"/shorts/", "/embed/", "/v/", "/live/"}
+
+// YouTube resolves watch, youtu.be, shorts, embed and playlist URLs
+type YouTube struct{}
+
+func (YouTube) ResolveID(id string) (Reference, bool) {
+	if !youtubeIDPattern.MatchString(id) {
+		return Reference{}, false
+	}
+	return Reference{Site: SiteYouTube, ID: id}, true
+}
+
+func (y YouTube) Resolve(u *url.URL) (Reference, bool, error) {
+	host := strings.ToLower(u.Hostname())
+	query := u.Query()
+
+	var id string
+	switch {
+	case host == "youtu.be" || host == "www.youtu.be":
+		id = strings.Trim(u.Path, "/")
+	case youtubeHosts[host] && u.Path == "/watch":
+		id = query.Get("v")
+	case youtubeHosts[host] && u.Path == "/playlist":
+		if query.Get("list") == "" {
+			return Reference{}, true, fmt.Errorf("%w: the playlist link is missing its list", ErrUnsupportedURL)
+		}
+	case youtubeHosts[host]:
+		for _, prefix := range youtubePathPrefixes {
+			if rest, ok := strings.CutPrefix(u.Path, prefix); ok {
+				id, _, _ = strings.Cut(rest, "/")
+				break
+			}
+		}
+		if id == "" {
+			return Reference{}, true, fmt.Errorf("%w: %s", ErrUnsupportedURL, u)
+		}
+	default:
+		return Reference{}, false, nil
+	}
+
+	ref := Reference{Site: SiteYouTube, ListID: query.Get("list")}
+	if id == "" && ref.ListID == "" {
+		return Reference{}, true, fmt.Errorf("%w: the link is missing its video", ErrUnsupportedURL)
+	}
+	if id != "" {
+		if _, ok := y.ResolveID(id); !ok {
+			return Reference{}, true, fmt.Errorf("%w: %s", ErrInvalidVideoID, id)
+		}
+		ref.ID = id
+	}
+	// a malformed timestamp shouldn't stop anyone from getting the video
+	if start, err := youtube.ParseTimestamp(query.Get("t")); err == nil {
+		ref.Start = start
+	}
+	return ref, true, nil
+}
@@ -0,0 +1,106 @@
+package media
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestYouTubeResolve(t *testing.T) {
+	chain := NewChain(YouTube{})
+	tests := []struct {
+		name    string
+		raw     string
+		want    Reference
+		wantErr error
+	}{
+		{
+			name: "bare id",
+			raw:  "dQw4w9WgXcQ",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ"},
+		},
+		{
+			name: "watch",
+			raw:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ"},
+		},
+		{
+			name: "watch without scheme",
+			raw:  "youtube.com/watch?v=dQw4w9WgXcQ",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ"},
+		},
+		{
+			name: "watch with timestamp and playlist",
+			raw:  "https://m.youtube.com/watch?v=dQw4w9WgXcQ&t=1m30s&list=PL123",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ", Start: 90 * time.Second, ListID: "PL123"},
+		},
+		{
+			name: "short link",
+			raw:  "https://youtu.be/dQw4w9WgXcQ?t=42",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ", Start: 42 * time.Second},
+		},
+		{
+			name: "shorts",
+			raw:  "https://www.youtube.com/shorts/dQw4w9WgXcQ",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ"},
+		},
+		{
+			name: "embed",
+			raw:  "https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ"},
+		},
+		{
+			name: "playlist",
+			raw:  "https://www.youtube.com/playlist?list=PL123",
+			want: Reference{Site: SiteYouTube, ListID: "PL123"},
+		},
+		{
+			name: "malformed timestamp is ignored",
+			raw:  "https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=abc",
+			want: Reference{Site: SiteYouTube, ID: "dQw4w9WgXcQ"},
+		},
+		{
+			name:    "watch without video or playlist",
+			raw:     "https://www.youtube.com/watch?feature=share",
+			wantErr: ErrUnsupportedURL,
+		},
+		{
+			name:    "playlist without list",
+			raw:     "https://www.youtube.com/playlist",
+			wantErr: ErrUnsupportedURL,
+		},
+		{
+			name:    "invalid video id",
+			raw:     "https://www.youtube.com/watch?v=tooshort",
+			wantErr: ErrInvalidVideoID,
+		},
+		{
+			name:    "channel page",
+			raw:     "https://www.youtube.com/@someone",
+			wantErr: ErrUnsupportedURL,
+		},
+		{
+			name:    "other site",
+			raw:     "https://example.com/watch?v=dQw4w9WgXcQ",
+			wantErr: ErrUnsupportedURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chain.Resolve(tt.raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Resolve(%q) error = %v, want %v", tt.raw, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS talking_stick_participants CASCADE;
+DROP TABLE IF EXISTS talking_stick_sessions CASCADE;
+
+COMMIT;
@@ -0,0 +1,50 @@
+-- Start a transaction
+BEGIN;
+
+--
+-- Define database schema
+--
+
+CREATE TABLE IF NOT EXISTS talking_stick_sessions
+(
+    session_id BIGSERIAL NOT NULL,
+    guild_id   TEXT      NOT NULL,
+    channel_id TEXT      NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    ended_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (session_id),
+    CONSTRAINT fk_guild
+        FOREIGN KEY (guild_id)
+            REFERENCES guilds (guild_id)
+            ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS talking_stick_participants
+(
+    session_id    BIGINT    NOT NULL,
+    user_id       TEXT      NOT NULL,
+    turns_taken   INTEGER   NOT NULL DEFAULT 0,
+    turns_skipped INTEGER   NOT NULL DEFAULT 0,
+    time_held_ms  BIGINT    NOT NULL DEFAULT 0,
+    last_held_at  TIMESTAMP NULL,
+    PRIMARY KEY (session_id, user_id),
+    CONSTRAINT fk_session
+        FOREIGN KEY (session_id)
+            REFERENCES talking_stick_sessions (session_id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS talking_stick_sessions_guild_id_idx ON talking_stick_sessions (guild_id);
+
+COMMENT ON COLUMN talking_stick_participants.last_held_at is 'The last time the member was holding the talking stick during the session';
+
+--
+-- Grant permissions
+--
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON talking_stick_sessions TO discord_bot;
+GRANT SELECT, INSERT, UPDATE, DELETE ON talking_stick_participants TO discord_bot;
+GRANT USAGE, SELECT ON SEQUENCE talking_stick_sessions_session_id_seq TO discord_bot;
+
+-- Commit transaction
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS update_talking_stick_settings_updated_at_trigger ON talking_stick_settings;
+DROP TABLE IF EXISTS talking_stick_settings CASCADE;
+
+COMMIT;
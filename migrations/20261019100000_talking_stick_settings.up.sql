@@ -0,0 +1,39 @@
+-- Start a transaction
+BEGIN;
+
+--
+-- Define database schema
+--
+
+CREATE TABLE IF NOT EXISTS talking_stick_settings
+(
+    guild_id       TEXT      NOT NULL,
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    order_strategy TEXT      NOT NULL DEFAULT 'random',
+    PRIMARY KEY (guild_id),
+    CONSTRAINT fk_guild
+        FOREIGN KEY (guild_id)
+            REFERENCES guilds (guild_id)
+            ON DELETE CASCADE
+);
+
+COMMENT ON COLUMN talking_stick_settings.order_strategy is 'Speaking order used when a session is started without one';
+
+--
+-- Create triggers
+--
+
+CREATE OR REPLACE TRIGGER update_talking_stick_settings_updated_at_trigger
+    BEFORE UPDATE
+    ON talking_stick_settings
+    FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+--
+-- Grant permissions
+--
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON talking_stick_settings TO discord_bot;
+
+-- Commit transaction
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE talking_stick_settings
+    DROP COLUMN IF EXISTS turn_duration_seconds,
+    DROP COLUMN IF EXISTS inactivity_timeout_seconds,
+    DROP COLUMN IF EXISTS strict,
+    DROP COLUMN IF EXISTS panel_channel_id;
+
+COMMIT;
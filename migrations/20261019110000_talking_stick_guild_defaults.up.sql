@@ -0,0 +1,18 @@
+-- Start a transaction
+BEGIN;
+
+--
+-- Define database schema
+--
+
+ALTER TABLE talking_stick_settings
+    ADD COLUMN IF NOT EXISTS turn_duration_seconds      INTEGER NOT NULL DEFAULT 15,
+    ADD COLUMN IF NOT EXISTS inactivity_timeout_seconds INTEGER NOT NULL DEFAULT 900,
+    ADD COLUMN IF NOT EXISTS strict                     BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS panel_channel_id           TEXT    NULL;
+
+COMMENT ON COLUMN talking_stick_settings.inactivity_timeout_seconds is 'How long a session can go without any interaction before it is closed';
+COMMENT ON COLUMN talking_stick_settings.panel_channel_id is 'Channel control panels are posted in, defaults to the voice channel''s chat when null';
+
+-- Commit transaction
+COMMIT;
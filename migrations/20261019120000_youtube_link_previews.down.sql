@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE channels
+    DROP COLUMN IF EXISTS youtube_previews,
+    DROP COLUMN IF EXISTS youtube_suppress_embeds;
+
+COMMIT;
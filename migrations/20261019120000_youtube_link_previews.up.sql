@@ -0,0 +1,16 @@
+-- Start a transaction
+BEGIN;
+
+--
+-- Define database schema
+--
+
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS youtube_previews        BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS youtube_suppress_embeds BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN channels.youtube_previews is 'Indicates whether YouTube links posted in the channel get a preview with download buttons';
+COMMENT ON COLUMN channels.youtube_suppress_embeds is 'Indicates whether discord''s own embed is removed from messages the bot previews';
+
+-- Commit transaction
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS youtube_search_cache;
+DROP TABLE IF EXISTS youtube_quota_usage;
+
+COMMIT;
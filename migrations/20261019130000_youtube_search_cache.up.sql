@@ -0,0 +1,31 @@
+-- Start a transaction
+BEGIN;
+
+--
+-- Define database schema
+--
+
+CREATE TABLE IF NOT EXISTS youtube_search_cache
+(
+    cache_key  TEXT      NOT NULL,
+    results    JSONB     NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (cache_key)
+);
+
+COMMENT ON COLUMN youtube_search_cache.cache_key is 'Normalized query and filters of the search';
+
+CREATE TABLE IF NOT EXISTS youtube_quota_usage
+(
+    day   DATE    NOT NULL,
+    units INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (day)
+);
+
+COMMENT ON COLUMN youtube_quota_usage.day is 'Day in pacific time, when the Data API quota resets';
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON youtube_search_cache TO discord_bot;
+GRANT SELECT, INSERT, UPDATE, DELETE ON youtube_quota_usage TO discord_bot;
+
+-- Commit transaction
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS talking_stick_mutes;
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- Start a transaction
+BEGIN;
+
+--
+-- Define database schema
+--
+
+CREATE TABLE IF NOT EXISTS talking_stick_mutes
+(
+    guild_id      TEXT    NOT NULL,
+    user_id       TEXT    NOT NULL,
+    original_mute BOOLEAN NOT NULL,
+    PRIMARY KEY (guild_id, user_id)
+);
+
+COMMENT ON COLUMN talking_stick_mutes.original_mute is 'The server mute state the member had before a strict session muted them';
+
+--
+-- Grant permissions
+--
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON talking_stick_mutes TO discord_bot;
+
+-- Commit transaction
+COMMIT;
@@ -0,0 +1,85 @@
+package talkingstick
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+)
+
+// SkipRules determines which members are skipped when the talking stick is passed around
+type SkipRules struct {
+	// SelfMuted skips members that muted themselves
+	SelfMuted bool
+	// SelfDeafened skips members that deafened themselves
+	SelfDeafened bool
+	// Away skips members that are idle, in the AFK channel, or no longer in the voice channel
+	Away bool
+}
+
+func (r SkipRules) any() bool {
+	return r.SelfMuted || r.SelfDeafened || r.Away
+}
+
+// nextAvailable returns the next member in line that isn't skipped by the session's rules, recording who was
+// skipped and why. If everyone is skipped the next member in line gets the stick anyway. The caller must hold tss.mu
+func (tss *tsSession) nextAvailable() *tsMember {
+	tss.skipped = tss.skipped[:0]
+	if !tss.skip.any() {
+		return tss.stickholder.next
+	}
+
+	for current := tss.stickholder.next; current != tss.stickholder; current = current.next {
+		reason := tss.skipReason(current.data.User.ID)
+		if reason == "" {
+			return current
+		}
+		tss.skipped = append(tss.skipped, fmt.Sprintf("%s (%s)", current.data.Mention(), reason))
+	}
+	return tss.stickholder.next
+}
+
+// skipReason explains why the member should be skipped, or returns an empty string if they are available
+func (tss *tsSession) skipReason(userID string) string {
+	vs, err := tss.sess.State.VoiceState(tss.guildID, userID)
+	if err == nil && vs.ChannelID != tss.channelID && tss.inAFKChannel(vs) {
+		if tss.skip.Away {
+			return "in the AFK channel"
+		}
+		return ""
+	}
+	if err != nil || vs.ChannelID != tss.channelID {
+		if tss.skip.Away {
+			return "left the channel"
+		}
+		return ""
+	}
+
+	switch {
+	case tss.skip.SelfDeafened && vs.SelfDeaf:
+		return "deafened"
+	case tss.skip.SelfMuted && vs.SelfMute:
+		return "muted"
+	case tss.skip.Away && tss.isAway(userID):
+		return "away"
+	}
+	return ""
+}
+
+// inAFKChannel reports whether the member moved to the guild's AFK channel
+func (tss *tsSession) inAFKChannel(vs *discordgo.VoiceState) bool {
+	guild, err := tss.sess.State.Guild(tss.guildID)
+	return err == nil && guild.AfkChannelID != "" && vs.ChannelID == guild.AfkChannelID
+}
+
+func (tss *tsSession) isAway(userID string) bool {
+	presence, err := tss.sess.State.Presence(tss.guildID, userID)
+	return err == nil && presence.Status == discordgo.StatusIdle
+}
+
+// skippedSnapshot returns who was skipped the last time the stick was passed
+func (tss *tsSession) skippedSnapshot() []string {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	skipped := make([]string, len(tss.skipped))
+	copy(skipped, tss.skipped)
+	return skipped
+}
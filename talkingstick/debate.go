@@ -0,0 +1,191 @@
+package talkingstick
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"strings"
+	"time"
+)
+
+var ErrNotEnoughDebaters = errors.New("a debate needs at least two debaters")
+var ErrNotDebateModerator = errors.New("only the debate moderator can perform this action")
+
+// DebateConfig describes how long each debater speaks for in every phase of a debate. Phases without a duration
+// fall back to the session's turn duration
+type DebateConfig struct {
+	Opening  time.Duration
+	Rebuttal time.Duration
+	Closing  time.Duration
+	// ModeratorID is the member running the debate, they don't debate and are the only one allowed to control it
+	ModeratorID string
+}
+
+type debatePhase struct {
+	name     string
+	duration time.Duration
+}
+
+type debateTurn struct {
+	member *tsMember
+	phase  int
+}
+
+// debate alternates the talking stick between two teams, every debater speaks once per phase
+type debate struct {
+	teams        [2][]*tsMember
+	phases       []debatePhase
+	schedule     []debateTurn
+	turn         int
+	moderatorID  string
+	announcement string
+}
+
+// newDebate splits the debaters into two teams and schedules every turn of the debate. The team that opens
+// alternates between phases so both teams get to speak first
+func newDebate(head *tsMember, cfg DebateConfig, turnDuration time.Duration) (*debate, error) {
+	var debaters []*tsMember
+	if head != nil {
+		for current := head; ; current = current.next {
+			debaters = append(debaters, current)
+			if current.next == head {
+				break
+			}
+		}
+	}
+	if len(debaters) < 2 {
+		return nil, ErrNotEnoughDebaters
+	}
+
+	d := &debate{
+		phases: []debatePhase{
+			{name: "Opening", duration: orDefault(cfg.Opening, turnDuration)},
+			{name: "Rebuttal", duration: orDefault(cfg.Rebuttal, turnDuration)},
+			{name: "Closing", duration: orDefault(cfg.Closing, turnDuration)},
+		},
+		moderatorID: cfg.ModeratorID,
+	}
+	for i, debater := range debaters {
+		d.teams[i%2] = append(d.teams[i%2], debater)
+	}
+
+	for phase := range d.phases {
+		first, second := d.teams[phase%2], d.teams[(phase+1)%2]
+		for i := 0; i < max(len(first), len(second)); i++ {
+			if i < len(first) {
+				d.schedule = append(d.schedule, debateTurn{member: first[i], phase: phase})
+			}
+			if i < len(second) {
+				d.schedule = append(d.schedule, debateTurn{member: second[i], phase: phase})
+			}
+		}
+	}
+	d.announcement = d.phaseAnnouncement()
+	return d, nil
+}
+
+// next moves on to the next debater, returning nil once the debate is over
+func (d *debate) next() *tsMember {
+	if d.turn+1 >= len(d.schedule) {
+		return nil
+	}
+	previous := d.schedule[d.turn].phase
+	d.turn++
+	if d.schedule[d.turn].phase != previous {
+		d.announcement = d.phaseAnnouncement()
+	}
+	return d.schedule[d.turn].member
+}
+
+func (d *debate) currentPhase() debatePhase {
+	return d.phases[d.schedule[d.turn].phase]
+}
+
+// setPhaseDuration changes how long each debater speaks for in the current phase
+func (d *debate) setPhaseDuration(duration time.Duration) {
+	d.phases[d.schedule[d.turn].phase].duration = duration
+	d.announcement = d.phaseAnnouncement()
+}
+
+func (d *debate) phaseAnnouncement() string {
+	phase := d.schedule[d.turn].phase
+	return fmt.Sprintf("📢 The %s phase has started (%d/%d), each debater has %s",
+		strings.ToLower(d.phases[phase].name), phase+1, len(d.phases), d.phases[phase].duration)
+}
+
+func (d *debate) teamList(team int) string {
+	mentions := make([]string, len(d.teams[team]))
+	for i, member := range d.teams[team] {
+		mentions[i] = member.data.Mention()
+	}
+	return strings.Join(mentions, ", ")
+}
+
+// upcoming returns who speaks after the current debater, or nil if they are the last one
+func (d *debate) upcoming() *tsMember {
+	if d.turn+1 >= len(d.schedule) {
+		return nil
+	}
+	return d.schedule[d.turn+1].member
+}
+
+// upcomingDebater describes who speaks after the current debater
+func (tss *tsSession) upcomingDebater() string {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	if next := tss.debate.upcoming(); next != nil {
+		return next.data.Mention()
+	}
+	return "The debate ends"
+}
+
+// debateEmbed adds the phase announcement and the teams to the control panel
+func (tss *tsSession) debateEmbed(embed *discordgo.MessageEmbed) {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+
+	d := tss.debate
+	embed.Description = d.announcement
+	phase := d.schedule[d.turn].phase
+	embed.Fields = append(embed.Fields,
+		&discordgo.MessageEmbedField{
+			Name:   "Phase",
+			Value:  fmt.Sprintf("%s (%d/%d)", d.phases[phase].name, phase+1, len(d.phases)),
+			Inline: false,
+		},
+		&discordgo.MessageEmbedField{Name: "Team A", Value: d.teamList(0), Inline: true},
+		&discordgo.MessageEmbedField{Name: "Team B", Value: d.teamList(1), Inline: true},
+	)
+	if d.moderatorID != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Moderator",
+			Value:  fmt.Sprintf("<@%s>", d.moderatorID),
+			Inline: false,
+		})
+	}
+}
+
+// nextDebater passes the stick to the next debater, switching the turn duration when a new phase starts.
+// The caller must hold tss.mu
+func (tss *tsSession) nextDebater() *tsMember {
+	next := tss.debate.next()
+	if next != nil {
+		tss.turnDuration = tss.debate.currentPhase().duration
+	}
+	return next
+}
+
+// isDebateModerator reports whether the user is allowed to control a debate
+func (tss *tsSession) isDebateModerator(userID string) bool {
+	if tss.debate == nil || tss.debate.moderatorID == "" {
+		return true // nobody is moderating, anyone can
+	}
+	return tss.debate.moderatorID == userID
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
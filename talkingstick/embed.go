@@ -1,8 +1,11 @@
 package talkingstick
 
 import (
+	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"log/slog"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -13,7 +16,7 @@ func (tss *tsSession) CreateControlPanel() error {
 		Embeds:     []*discordgo.MessageEmbed{getEmbed(tss)},
 		Components: getComponents(tss),
 	}
-	msg, err := tss.sess.ChannelMessageSendComplex(tss.channelID, message)
+	msg, err := tss.sess.ChannelMessageSendComplex(tss.panelID, message)
 	tss.embed = msg
 	return err
 }
@@ -22,7 +25,7 @@ func (tss *tsSession) RefreshControlPanel() {
 	slog.Debug("refreshing control panel", "channel_id", tss.channelID)
 
 	edit := &discordgo.MessageEdit{
-		Channel:    tss.channelID,
+		Channel:    tss.panelID,
 		ID:         tss.embed.ID,
 		Embeds:     []*discordgo.MessageEmbed{getEmbed(tss)},
 		Components: getComponents(tss),
@@ -32,12 +35,27 @@ func (tss *tsSession) RefreshControlPanel() {
 	}
 }
 
+// RepostControlPanel replaces the control panel with a new one at the bottom of the channel
+func (tss *tsSession) RepostControlPanel() error {
+	slog.Debug("reposting control panel", "channel_id", tss.channelID)
+
+	previous := tss.embed
+	if err := tss.CreateControlPanel(); err != nil {
+		tss.embed = previous
+		return fmt.Errorf("create control panel: %w", err)
+	}
+	if err := tss.sess.ChannelMessageDelete(tss.panelID, previous.ID); err != nil {
+		slog.Warn("failed to delete previous control panel", "channel_id", tss.channelID, "error", err)
+	}
+	return nil
+}
+
 func (tss *tsSession) DecommissionControlPanel() error {
 	slog.Debug("decommissioning control panel", "channel_id", tss.channelID)
 
 	duration := time.Since(tss.startTime).Round(time.Second)
 	edit := &discordgo.MessageEdit{
-		Channel: tss.channelID,
+		Channel: tss.panelID,
 		ID:      tss.embed.ID,
 		Embeds: []*discordgo.MessageEmbed{{
 			Title:       "Talking Stick Session Ended",
@@ -57,9 +75,97 @@ func (tss *tsSession) DecommissionControlPanel() error {
 	return err
 }
 
+// PublishReport posts a summary of how everyone used the talking stick during the session. The caller must hold tss.mu
+func (tss *tsSession) PublishReport() error {
+	slog.Debug("publishing session report", "channel_id", tss.channelID)
+
+	stats := make([]MemberStats, 0, len(tss.stats))
+	for _, s := range tss.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].TimeHeld > stats[j].TimeHeld })
+
+	embed := StatsEmbed("Talking Stick Session Report", stats)
+	embed.Description = fmt.Sprintf("Session lasted %s", time.Since(tss.startTime).Round(time.Second))
+	_, err := tss.sess.ChannelMessageSendEmbed(tss.panelID, embed)
+	return err
+}
+
+// StatsEmbed renders a line per member with the number of turns they took, skipped, and how long they held the stick.
+// Members are ranked in the order given, longest time held first
+func StatsEmbed(title string, stats []MemberStats) *discordgo.MessageEmbed {
+	fields := make([]*discordgo.MessageEmbedField, 0, len(stats))
+	for i, s := range stats {
+		if len(fields) == 25 {
+			break // discord doesn't allow more than 25 fields
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: fmt.Sprintf("#%d", i+1),
+			Value: fmt.Sprintf("<@%s>\nTurns: %d | Skipped: %d | Time: %s",
+				s.UserID, s.TurnsTaken, s.TurnsSkipped, s.TimeHeld.Round(time.Second)),
+			Inline: false,
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  title,
+		Color:  0x0000FF, // Blue
+		Fields: fields,
+	}
+	if len(fields) == 0 {
+		embed.Description = "Nobody has held the talking stick yet"
+	}
+	return embed
+}
+
+// OrderEmbed lists who will be holding the talking stick and in what order
+func (tss *tsSession) OrderEmbed() *discordgo.MessageEmbed {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("🎤 %s\n", tss.stickholder.data.Mention()))
+	switch tss.mode {
+	case ModeQueue:
+		for i, member := range tss.queue {
+			builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, member.Mention()))
+		}
+	case ModeDebate:
+		d := tss.debate
+		for i, turn := range d.schedule[d.turn+1:] {
+			builder.WriteString(fmt.Sprintf("%d. %s (%s)\n", i+1, turn.member.data.Mention(), d.phases[turn.phase].name))
+		}
+	default:
+		i := 1
+		for current := tss.stickholder.next; current != tss.stickholder; current = current.next {
+			builder.WriteString(fmt.Sprintf("%d. %s\n", i, current.data.Mention()))
+			i++
+		}
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "Talking Stick Speaking Order",
+		Description: builder.String(),
+		Color:       0x00FF00, // Green color
+	}
+}
+
 func getEmbed(tss *tsSession) *discordgo.MessageEmbed {
 	stickholder := tss.stickholder
-	return &discordgo.MessageEmbed{
+	queue := tss.queueSnapshot()
+
+	nextSpeaker := stickholder.next.data.Mention()
+	switch tss.mode {
+	case ModeQueue:
+		nextSpeaker = "Nobody has raised their hand"
+		if len(queue) != 0 {
+			nextSpeaker = queue[0].Mention()
+		}
+	case ModeDebate:
+		nextSpeaker = tss.upcomingDebater()
+	}
+
+	embed := &discordgo.MessageEmbed{
 		Title: "Talking Stick Session",
 		Color: 0x00FF00, // Green color
 		Fields: []*discordgo.MessageEmbedField{
@@ -70,9 +176,19 @@ func getEmbed(tss *tsSession) *discordgo.MessageEmbed {
 			},
 			{
 				Name:   "Next Speaker",
-				Value:  stickholder.next.data.Mention(),
+				Value:  nextSpeaker,
 				Inline: false,
 			},
+			{
+				Name:   "Time Left",
+				Value:  tss.turnStatus(),
+				Inline: true,
+			},
+			{
+				Name:   "Turn Duration",
+				Value:  tss.getTurnDuration().String(),
+				Inline: true,
+			},
 			{
 				Name:   "Session Status",
 				Value:  getStatusEmoji(tss.Running()),
@@ -80,16 +196,35 @@ func getEmbed(tss *tsSession) *discordgo.MessageEmbed {
 			},
 		},
 	}
+	if skipped := tss.skippedSnapshot(); len(skipped) != 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Skipped",
+			Value:  strings.Join(skipped, "\n"),
+			Inline: false,
+		})
+	}
+	if tss.mode == ModeQueue {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Raised Hands",
+			Value:  getQueueList(queue),
+			Inline: false,
+		})
+	}
+	if tss.mode == ModeDebate {
+		tss.debateEmbed(embed)
+	}
+	return embed
 }
 
 func getComponents(tss *tsSession) []discordgo.MessageComponent {
-	return []discordgo.MessageComponent{
+	channelID := tss.channelID
+	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "Quit",
 					Style:    discordgo.DangerButton,
-					CustomID: "talking_stick_quit",
+					CustomID: getCustomID("talking_stick_quit", channelID),
 					Emoji: discordgo.ComponentEmoji{
 						Name: "❌",
 					},
@@ -97,7 +232,7 @@ func getComponents(tss *tsSession) []discordgo.MessageComponent {
 				discordgo.Button{
 					Label:    getPlayPauseLabel(tss.Running()),
 					Style:    discordgo.PrimaryButton,
-					CustomID: "talking_stick_playpause",
+					CustomID: getCustomID("talking_stick_playpause", channelID),
 					Emoji: discordgo.ComponentEmoji{
 						Name: getPlayPauseEmoji(tss.Running()),
 					},
@@ -105,13 +240,41 @@ func getComponents(tss *tsSession) []discordgo.MessageComponent {
 				discordgo.Button{
 					Label:    "Next Speaker",
 					Style:    discordgo.SecondaryButton,
-					CustomID: "talking_stick_next",
+					CustomID: getCustomID("talking_stick_next", channelID),
 					Emoji: discordgo.ComponentEmoji{
 						Name: "⏭️",
 					},
 				},
 			},
 		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "-15s",
+					Style:    discordgo.SecondaryButton,
+					CustomID: getCustomID("talking_stick_time_down", channelID),
+					Emoji: discordgo.ComponentEmoji{
+						Name: "⏪",
+					},
+				},
+				discordgo.Button{
+					Label:    "+15s",
+					Style:    discordgo.SecondaryButton,
+					CustomID: getCustomID("talking_stick_time_up", channelID),
+					Emoji: discordgo.ComponentEmoji{
+						Name: "⏩",
+					},
+				},
+				discordgo.Button{
+					Label:    "Extend my turn",
+					Style:    discordgo.SuccessButton,
+					CustomID: getCustomID("talking_stick_extend", channelID),
+					Emoji: discordgo.ComponentEmoji{
+						Name: "⏳",
+					},
+				},
+			},
+		},
 		//discordgo.ActionsRow{
 		//	Components: []discordgo.MessageComponent{
 		//		discordgo.SelectMenu{
@@ -122,6 +285,83 @@ func getComponents(tss *tsSession) []discordgo.MessageComponent {
 		//	},
 		//},
 	}
+	if tss.mode == ModeQueue {
+		components = append(components, getQueueComponents(channelID, tss.queueSnapshot())...)
+	}
+	return components
+}
+
+func getQueueComponents(channelID string, queue []*discordgo.Member) []discordgo.MessageComponent {
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Raise hand",
+					Style:    discordgo.SuccessButton,
+					CustomID: getCustomID("talking_stick_raise", channelID),
+					Emoji: discordgo.ComponentEmoji{
+						Name: "✋",
+					},
+				},
+				discordgo.Button{
+					Label:    "Lower hand",
+					Style:    discordgo.SecondaryButton,
+					CustomID: getCustomID("talking_stick_lower", channelID),
+					Emoji: discordgo.ComponentEmoji{
+						Name: "👇",
+					},
+				},
+			},
+		},
+	}
+
+	// there's nothing to reorder unless at least two hands are raised
+	if len(queue) < 2 {
+		return components
+	}
+	options := make([]discordgo.SelectMenuOption, 0, len(queue))
+	for i, member := range queue {
+		if i == 25 {
+			break // discord doesn't allow more than 25 options
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label: getDisplayName(member),
+			Value: member.User.ID,
+		})
+	}
+	return append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				MenuType:    discordgo.StringSelectMenu,
+				CustomID:    getCustomID("talking_stick_bump", channelID),
+				Placeholder: "Moderators: move to the front of the queue...",
+				Options:     options,
+			},
+		},
+	})
+}
+
+func getQueueList(queue []*discordgo.Member) string {
+	if len(queue) == 0 {
+		return "No hands raised"
+	}
+	builder := strings.Builder{}
+	for i, member := range queue {
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, member.Mention()))
+	}
+	return builder.String()
+}
+
+func getDisplayName(member *discordgo.Member) string {
+	if member.Nick != "" {
+		return member.Nick
+	}
+	return member.User.Username
+}
+
+// getCustomID scopes a component to the session's channel, so it works no matter where the member pressing it is
+func getCustomID(name, channelID string) string {
+	return name + ":" + channelID
 }
 
 func getStatusEmoji(isRunning bool) string {
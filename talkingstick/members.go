@@ -11,6 +11,11 @@ type tsMember struct {
 	next *tsMember
 }
 
+type memberKey struct {
+	guildID string
+	userID  string
+}
+
 func loadVoiceMembers(s *discordgo.Session, guildID, channelID string) []*discordgo.Member {
 	guild, err := s.State.Guild(guildID)
 	if err != nil {
@@ -70,3 +75,15 @@ func getMember(head *tsMember, target string) (*tsMember, bool) {
 		}
 	}
 }
+
+func removeMember(members []*discordgo.Member, userID string) []*discordgo.Member {
+	if userID == "" {
+		return members
+	}
+	for i, member := range members {
+		if member.User.ID == userID {
+			return append(members[:i], members[i+1:]...)
+		}
+	}
+	return members
+}
@@ -0,0 +1,153 @@
+package talkingstick
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"sync"
+)
+
+// muteLedger keeps track of the server mute state members had before a strict session muted them. Members are
+// only forgotten once their original state is restored, if that fails (ie. they left voice) the restore is retried
+// the next time they connect to a voice channel. The original states are kept in postgres so members muted when the
+// bot crashed or restarted are restored once it's back up.
+type muteLedger struct {
+	mu       *sync.Mutex
+	sess     *discordgo.Session
+	db       *sqlx.DB
+	original map[memberKey]bool
+	pending  map[memberKey]bool
+}
+
+func newMuteLedger(s *discordgo.Session, db *sqlx.DB) *muteLedger {
+	return &muteLedger{
+		mu:       &sync.Mutex{},
+		sess:     s,
+		db:       db,
+		original: make(map[memberKey]bool),
+		pending:  make(map[memberKey]bool),
+	}
+}
+
+// RestoreLeftover restores the members a previous run of the bot left muted. Members that can't be restored right
+// now are retried the next time they connect to a voice channel
+func (l *muteLedger) RestoreLeftover() {
+	var rows []struct {
+		GuildID      string `db:"guild_id"`
+		UserID       string `db:"user_id"`
+		OriginalMute bool   `db:"original_mute"`
+	}
+	if err := l.db.Select(&rows, `SELECT guild_id, user_id, original_mute FROM talking_stick_mutes`); err != nil {
+		slog.Error("failed to load leftover mutes", "error", err)
+		return
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	slog.Info("restoring members left muted by a previous run", "count", len(rows))
+	l.mu.Lock()
+	for _, row := range rows {
+		key := memberKey{guildID: row.GuildID, userID: row.UserID}
+		if _, ok := l.original[key]; !ok {
+			l.original[key] = row.OriginalMute
+		}
+	}
+	l.mu.Unlock()
+
+	for _, row := range rows {
+		if err := l.Restore(row.GuildID, row.UserID); err != nil {
+			slog.Debug("leftover mute restore is pending", "guild_id", row.GuildID, "user_id", row.UserID, "error", err)
+		}
+	}
+}
+
+// Mute server mutes the member, recording their original mute state the first time they are seen
+func (l *muteLedger) Mute(guildID, userID string) error {
+	key := memberKey{guildID: guildID, userID: userID}
+	l.track(key)
+	if err := l.sess.GuildMemberMute(guildID, userID, true); err != nil {
+		return fmt.Errorf("mute: %w", err)
+	}
+	return nil
+}
+
+// Release lets the member speak by setting them back to their original mute state. The member is still tracked
+func (l *muteLedger) Release(guildID, userID string) error {
+	key := memberKey{guildID: guildID, userID: userID}
+	original := l.track(key)
+	if err := l.sess.GuildMemberMute(guildID, userID, original); err != nil {
+		return fmt.Errorf("release: %w", err)
+	}
+	return nil
+}
+
+// Restore sets the member back to their original mute state and stops tracking them
+func (l *muteLedger) Restore(guildID, userID string) error {
+	key := memberKey{guildID: guildID, userID: userID}
+
+	l.mu.Lock()
+	original, ok := l.original[key]
+	l.mu.Unlock()
+	if !ok {
+		return nil // we never touched this member
+	}
+
+	if err := l.sess.GuildMemberMute(guildID, userID, original); err != nil {
+		l.mu.Lock()
+		l.pending[key] = true
+		l.mu.Unlock()
+		return fmt.Errorf("restore: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.original, key)
+	delete(l.pending, key)
+	if _, err := l.db.Exec(`DELETE FROM talking_stick_mutes WHERE guild_id = $1 AND user_id = $2`,
+		guildID, userID); err != nil {
+		slog.Error("failed to delete restored mute", "guild_id", guildID, "user_id", userID, "error", err)
+	}
+	return nil
+}
+
+// RetryPending attempts to restore a member whose previous restore failed
+func (l *muteLedger) RetryPending(guildID, userID string) {
+	key := memberKey{guildID: guildID, userID: userID}
+
+	l.mu.Lock()
+	isPending := l.pending[key]
+	l.mu.Unlock()
+	if !isPending {
+		return
+	}
+
+	slog.Debug("retrying pending mute restore", "guild_id", guildID, "user_id", userID)
+	if err := l.Restore(guildID, userID); err != nil {
+		slog.Error("failed to restore mute state", "guild_id", guildID, "user_id", userID, "error", err)
+	}
+}
+
+// track records the members current mute state if they aren't already tracked and returns their original state.
+// The state is saved before the member is muted so it outlives the process
+func (l *muteLedger) track(key memberKey) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if original, ok := l.original[key]; ok {
+		return original
+	}
+
+	original := false
+	if vs, err := l.sess.State.VoiceState(key.guildID, key.userID); err == nil {
+		original = vs.Mute
+	}
+	l.original[key] = original
+
+	query := `INSERT INTO talking_stick_mutes (guild_id, user_id, original_mute) VALUES ($1, $2, $3)
+				ON CONFLICT (guild_id, user_id) DO NOTHING`
+	if _, err := l.db.Exec(query, key.guildID, key.userID, original); err != nil {
+		slog.Error("failed to save original mute state", "guild_id", key.guildID, "user_id", key.userID, "error", err)
+	}
+	return original
+}
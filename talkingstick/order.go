@@ -0,0 +1,149 @@
+package talkingstick
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OrderStrategy determines the order members hold the talking stick in
+type OrderStrategy string
+
+var (
+	// OrderRandom shuffles the members
+	OrderRandom OrderStrategy = "random"
+	// OrderJoin goes in the order members joined the voice channel
+	OrderJoin OrderStrategy = "join"
+	// OrderAlphabetical goes in alphabetical order of display name
+	OrderAlphabetical OrderStrategy = "alphabetical"
+	// OrderRolePriority puts members with the highest roles (ie. hosts) first
+	OrderRolePriority OrderStrategy = "role-priority"
+	// OrderLeastRecent puts members that haven't held the talking stick in the longest time first
+	OrderLeastRecent OrderStrategy = "least-recent"
+)
+
+// joinTracker remembers when members joined their current voice channel
+type joinTracker struct {
+	mu     *sync.Mutex
+	joined map[memberKey]time.Time
+}
+
+func newJoinTracker() *joinTracker {
+	return &joinTracker{
+		mu:     &sync.Mutex{},
+		joined: make(map[memberKey]time.Time),
+	}
+}
+
+func (t *joinTracker) Update(e *discordgo.VoiceStateUpdate) {
+	key := memberKey{guildID: e.GuildID, userID: e.UserID}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e.ChannelID == "" {
+		delete(t.joined, key)
+		return
+	}
+	if e.BeforeUpdate == nil || e.BeforeUpdate.ChannelID != e.ChannelID {
+		t.joined[key] = time.Now()
+	}
+}
+
+// Get returns when the member joined their voice channel, members that joined before the bot started are unknown
+func (t *joinTracker) Get(guildID, userID string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	joined, ok := t.joined[memberKey{guildID: guildID, userID: userID}]
+	return joined, ok
+}
+
+// orderMembers sorts the members according to the strategy. Members are shuffled first so ties are broken randomly
+func (s *SessManager) orderMembers(guildID string, members []*discordgo.Member, strategy OrderStrategy) {
+	shuffleDGMembers(members)
+
+	switch strategy {
+	case OrderRandom, "":
+		return
+	case OrderJoin:
+		s.sortByJoinTime(guildID, members)
+	case OrderAlphabetical:
+		sort.SliceStable(members, func(i, j int) bool {
+			return strings.ToLower(getDisplayName(members[i])) < strings.ToLower(getDisplayName(members[j]))
+		})
+	case OrderRolePriority:
+		s.sortByRolePriority(guildID, members)
+	case OrderLeastRecent:
+		if err := s.sortByLastHeld(guildID, members); err != nil {
+			slog.Error("failed to sort members by last held", "guild_id", guildID, "error", err)
+		}
+	default:
+		slog.Warn("unknown order strategy, using random order", "strategy", strategy)
+	}
+}
+
+func (s *SessManager) sortByJoinTime(guildID string, members []*discordgo.Member) {
+	joined := make(map[string]time.Time, len(members))
+	for _, member := range members {
+		if t, ok := s.joins.Get(guildID, member.User.ID); ok {
+			joined[member.User.ID] = t
+		}
+	}
+
+	// members we didn't see join were there first
+	sort.SliceStable(members, func(i, j int) bool {
+		return joined[members[i].User.ID].Before(joined[members[j].User.ID])
+	})
+}
+
+func (s *SessManager) sortByRolePriority(guildID string, members []*discordgo.Member) {
+	positions := make(map[string]int, len(members))
+	for _, member := range members {
+		for _, roleID := range member.Roles {
+			role, err := s.sess.State.Role(guildID, roleID)
+			if err != nil {
+				continue
+			}
+			positions[member.User.ID] = max(positions[member.User.ID], role.Position)
+		}
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return positions[members[i].User.ID] > positions[members[j].User.ID]
+	})
+}
+
+func (s *SessManager) sortByLastHeld(guildID string, members []*discordgo.Member) error {
+	userIDs := make([]string, len(members))
+	for i, member := range members {
+		userIDs[i] = member.User.ID
+	}
+
+	var rows []struct {
+		UserID   string    `db:"user_id"`
+		LastHeld time.Time `db:"last_held_at"`
+	}
+	query := `SELECT p.user_id, MAX(p.last_held_at) AS last_held_at
+			FROM talking_stick_participants p
+			JOIN talking_stick_sessions s ON s.session_id = p.session_id
+			WHERE s.guild_id = $1 AND p.user_id = ANY($2) AND p.last_held_at IS NOT NULL
+			GROUP BY p.user_id`
+	if err := s.db.Select(&rows, query, guildID, pq.Array(userIDs)); err != nil {
+		return fmt.Errorf("select: %w", err)
+	}
+
+	lastHeld := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		lastHeld[row.UserID] = row.LastHeld
+	}
+
+	// members that never held the talking stick go first
+	sort.SliceStable(members, func(i, j int) bool {
+		return lastHeld[members[i].User.ID].Before(lastHeld[members[j].User.ID])
+	})
+	return nil
+}
@@ -0,0 +1,107 @@
+package talkingstick
+
+import (
+	"errors"
+	"github.com/bwmarrin/discordgo"
+	"log/slog"
+)
+
+var ErrAlreadyQueued = errors.New("member has already raised their hand")
+var ErrNotQueued = errors.New("member has not raised their hand")
+var ErrNotQueueMode = errors.New("session is not in queue mode")
+
+// RaiseHand adds the member to the back of the queue
+func (tss *tsSession) RaiseHand(member *discordgo.Member) error {
+	if tss.mode != ModeQueue {
+		return ErrNotQueueMode
+	}
+
+	tss.mu.Lock()
+	if queueIndex(tss.queue, member.User.ID) >= 0 {
+		tss.mu.Unlock()
+		return ErrAlreadyQueued
+	}
+	slog.Debug("member raised their hand", "channel_id", tss.channelID, "user_id", member.User.ID)
+	tss.queue = append(tss.queue, member)
+	tss.mu.Unlock()
+
+	tss.RefreshControlPanel()
+	return nil
+}
+
+// LowerHand removes the member from the queue
+func (tss *tsSession) LowerHand(userID string) error {
+	if tss.mode != ModeQueue {
+		return ErrNotQueueMode
+	}
+
+	tss.mu.Lock()
+	idx := queueIndex(tss.queue, userID)
+	if idx < 0 {
+		tss.mu.Unlock()
+		return ErrNotQueued
+	}
+	slog.Debug("member lowered their hand", "channel_id", tss.channelID, "user_id", userID)
+	tss.queue = append(tss.queue[:idx], tss.queue[idx+1:]...)
+	tss.mu.Unlock()
+
+	tss.RefreshControlPanel()
+	return nil
+}
+
+// BumpHand moves the member to the front of the queue
+func (tss *tsSession) BumpHand(userID string) error {
+	if tss.mode != ModeQueue {
+		return ErrNotQueueMode
+	}
+
+	tss.mu.Lock()
+	idx := queueIndex(tss.queue, userID)
+	if idx < 0 {
+		tss.mu.Unlock()
+		return ErrNotQueued
+	}
+	slog.Debug("moving member to the front of the queue", "channel_id", tss.channelID, "user_id", userID)
+	member := tss.queue[idx]
+	copy(tss.queue[1:idx+1], tss.queue[:idx])
+	tss.queue[0] = member
+	tss.mu.Unlock()
+
+	tss.RefreshControlPanel()
+	return nil
+}
+
+// nextInQueue removes the first raised hand from the queue and returns them. The caller must hold tss.mu
+func (tss *tsSession) nextInQueue() *tsMember {
+	if len(tss.queue) == 0 {
+		return nil
+	}
+	member := tss.queue[0]
+	tss.queue = tss.queue[1:]
+
+	// reuse the node if the member is already part of the session, otherwise add them after the stickholder
+	if node, ok := getMember(tss.stickholder, member.User.ID); ok {
+		return node
+	}
+	node := &tsMember{data: member, next: tss.stickholder.next}
+	tss.stickholder.next = node
+	return node
+}
+
+// queueSnapshot returns a copy of the queue that is safe to read without holding tss.mu
+func (tss *tsSession) queueSnapshot() []*discordgo.Member {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	queue := make([]*discordgo.Member, len(tss.queue))
+	copy(queue, tss.queue)
+	return queue
+}
+
+func queueIndex(queue []*discordgo.Member, userID string) int {
+	for i, member := range queue {
+		if member.User.ID == userID {
+			return i
+		}
+	}
+	return -1
+}
@@ -2,6 +2,7 @@ package talkingstick
 
 import (
 	"github.com/bwmarrin/discordgo"
+	"github.com/jmoiron/sqlx"
 	"log/slog"
 	"sync"
 	"time"
@@ -12,33 +13,72 @@ type tsSession struct {
 	ticker       *time.Ticker
 	startTime    time.Time
 	turnDuration time.Duration
+	idleTimeout  time.Duration
+	turnDeadline time.Time
+	turnLeft     time.Duration // time left on the turn while the session is paused
+	turnExtended bool
 
+	guildID    string
 	channelID  string
+	creatorID  string
+	panelID    string // channel the control panel is posted in
 	isRunning  bool
+	closed     bool // set once the session starts closing, nothing may mute or move members after that
 	shutdownCh chan struct{}
 	mu         *sync.Mutex
 	quitOnce   sync.Once
 
+	mode        Mode
 	stickholder *tsMember
+	queue       []*discordgo.Member
+	skip        SkipRules
+	skipped     []string
+	debate      *debate
+	stats       map[string]*MemberStats
+	turnStart   time.Time
+	turnHeld    time.Duration
+
+	strict bool
+	muted  map[string]bool
+	mutes  *muteLedger
+
+	stage    bool
+	speakers map[string]bool // whether members the session moved were originally stage speakers
 
 	embed *discordgo.Message
 	sess  *discordgo.Session
+	db    *sqlx.DB
 }
 
-func newTSSession(s *discordgo.Session, channelID string, duration time.Duration, head *tsMember) *tsSession {
+func newTSSession(s *discordgo.Session, db *sqlx.DB, guildID, channelID, creatorID string, cfg SessionConfig, head *tsMember, mutes *muteLedger) *tsSession {
 	return &tsSession{
-		staleTimer:   time.NewTimer(15 * time.Minute),
-		ticker:       time.NewTicker(duration),
+		staleTimer:   time.NewTimer(cfg.InactivityTimeout),
+		ticker:       time.NewTicker(cfg.TurnDuration),
 		startTime:    time.Now(),
-		turnDuration: duration,
+		turnDuration: cfg.TurnDuration,
+		idleTimeout:  cfg.InactivityTimeout,
+		guildID:      guildID,
 		channelID:    channelID,
+		creatorID:    creatorID,
+		panelID:      cfg.PanelChannelID,
 		isRunning:    false,
 		shutdownCh:   make(chan struct{}),
 		mu:           &sync.Mutex{},
 		quitOnce:     sync.Once{},
+		mode:         cfg.Mode,
 		stickholder:  head,
+		queue:        make([]*discordgo.Member, 0),
+		skip:         cfg.Skip,
+		skipped:      make([]string, 0),
+		stats:        newSessionStats(head),
+		strict:       cfg.Strict,
+		muted:        make(map[string]bool),
+		mutes:        mutes,
+		stage:        isStageChannel(s, channelID),
+		speakers:     make(map[string]bool),
 		embed:        nil,
 		sess:         s,
+		db:           db,
 	}
 }
 
@@ -46,6 +86,12 @@ func (tss *tsSession) Start() {
 	slog.Debug("starting talking stick session routine", "channel_id", tss.channelID)
 
 	defer tss.Close()
+	if tss.strict {
+		tss.enforceStrictMode()
+	}
+	if tss.stage {
+		tss.enforceStage()
+	}
 	for {
 		select {
 		case <-tss.shutdownCh:
@@ -61,32 +107,80 @@ func (tss *tsSession) Start() {
 	}
 }
 
+// Pass the talking stick to the target, or the next member if target is nil
 func (tss *tsSession) Pass(target *tsMember) {
+	tss.pass(target, false)
+}
+
+// Skip the rest of the stickholder's turn
+func (tss *tsSession) Skip() {
+	tss.pass(nil, true)
+}
+
+func (tss *tsSession) pass(target *tsMember, skipped bool) {
 	if !tss.Running() {
 		slog.Debug("session is paused, don't pass the talking stick")
 		return
 	}
 
 	tss.mu.Lock()
+	if tss.closed {
+		tss.mu.Unlock()
+		slog.Debug("session is closing, don't pass the talking stick")
+		return
+	}
+	if target == nil && tss.mode == ModeDebate {
+		if target = tss.nextDebater(); target == nil {
+			tss.endTurn(skipped)
+			tss.mu.Unlock()
+			slog.Info("debate is over, closing TS session", "channel_id", tss.channelID)
+			tss.Quit()
+			return
+		}
+	}
+	if target == nil && tss.mode == ModeQueue {
+		if target = tss.nextInQueue(); target == nil {
+			tss.resetTicker()
+			tss.mu.Unlock()
+			slog.Debug("nobody has raised their hand, the stickholder keeps the talking stick")
+			tss.RefreshControlPanel()
+			return
+		}
+	}
+
+	tss.endTurn(skipped)
+	previous := tss.stickholder.data.User
 	if target != nil {
 		tss.stickholder = target
 	} else {
-		tss.stickholder = tss.stickholder.next
+		tss.stickholder = tss.nextAvailable()
 	}
+	tss.resumeTurn()
+	tss.resetTicker()
 	tss.mu.Unlock()
 
 	stickholder := tss.stickholder.data.User
 	slog.Debug("passing the talking stick", "stickholder", stickholder.Username)
 
-	// set the priority speaker
-	if err := tss.sess.ChannelPermissionSet(tss.channelID, stickholder.ID,
+	// only the stickholder gets to speak in strict mode
+	if tss.strict && previous.ID != stickholder.ID {
+		tss.muteMember(previous.ID)
+		tss.releaseMember(stickholder.ID)
+	}
+
+	// stages have real speakers, everywhere else the stickholder is made a priority speaker
+	if tss.stage {
+		if previous.ID != stickholder.ID {
+			tss.setSpeaker(previous.ID, false)
+			tss.setSpeaker(stickholder.ID, true)
+		}
+	} else if err := tss.sess.ChannelPermissionSet(tss.channelID, stickholder.ID,
 		discordgo.PermissionOverwriteTypeMember, discordgo.PermissionVoicePrioritySpeaker, 0); err != nil {
 		slog.Error("failed to set priority speaker", "channel_id", tss.channelID, "user_id", stickholder.ID, "error", err)
 	}
 
 	// update the control panel
 	tss.RefreshControlPanel()
-	tss.resetTicker()
 }
 
 func (tss *tsSession) Pause() {
@@ -96,16 +190,19 @@ func (tss *tsSession) Pause() {
 		slog.Debug("pausing TS session", "channel_id", tss.channelID)
 		tss.isRunning = false
 		tss.ticker.Stop()
+		tss.turnLeft = time.Until(tss.turnDeadline)
+		tss.pauseTurn()
 	}
 }
 
 func (tss *tsSession) Play() {
 	tss.mu.Lock()
 	defer tss.mu.Unlock()
-	if !tss.isRunning {
+	if !tss.isRunning && !tss.closed {
 		slog.Debug("resuming TS session", "channel_id", tss.channelID)
 		tss.isRunning = true
-		tss.resetTicker()
+		tss.resumeTicker()
+		tss.resumeTurn()
 	}
 }
 
@@ -119,11 +216,21 @@ func (tss *tsSession) Close() {
 	tss.mu.Lock()
 	defer tss.mu.Unlock()
 
+	tss.closed = true
+	tss.isRunning = false
 	tss.ticker.Stop()
 	tss.staleTimer.Stop()
+	tss.endTurn(false)
 
-	// remove priority speaker
-	if err := tss.sess.ChannelPermissionSet(tss.channelID, tss.stickholder.data.User.ID,
+	// give everyone their voice back before anything else can go wrong
+	if tss.strict {
+		tss.restoreMutes()
+	}
+
+	// put the original stage speakers back, or remove priority speaker
+	if tss.stage {
+		tss.restoreSpeakers()
+	} else if err := tss.sess.ChannelPermissionSet(tss.channelID, tss.stickholder.data.User.ID,
 		discordgo.PermissionOverwriteTypeMember, 0, discordgo.PermissionVoicePrioritySpeaker); err != nil {
 		slog.Error("failed to remove priority speaker", "user_id", tss.stickholder.data.User.Username, "error", err)
 	}
@@ -132,6 +239,41 @@ func (tss *tsSession) Close() {
 	if err := tss.DecommissionControlPanel(); err != nil {
 		slog.Error("failed to decommission the tss control panel", "channel_id", tss.channelID, "error", err)
 	}
+
+	if err := tss.PublishReport(); err != nil {
+		slog.Error("failed to publish the tss report", "channel_id", tss.channelID, "error", err)
+	}
+	if err := tss.saveStats(tss.db); err != nil {
+		slog.Error("failed to save tss stats", "channel_id", tss.channelID, "error", err)
+	}
+}
+
+// isAuthorized reports whether the member is allowed to control the session. Participants, the session creator, and
+// moderators are all allowed
+func (tss *tsSession) isAuthorized(member *discordgo.Member) bool {
+	if member == nil || member.User == nil {
+		return false
+	}
+	if member.User.ID == tss.creatorID || isModerator(member) {
+		return true
+	}
+	if tss.debate != nil && tss.debate.moderatorID == member.User.ID {
+		return true
+	}
+
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	_, isParticipant := getMember(tss.stickholder, member.User.ID)
+	return isParticipant
+}
+
+// inChannel reports whether the member is in the session's voice channel
+func (tss *tsSession) inChannel(member *discordgo.Member) bool {
+	if member == nil || member.User == nil {
+		return false
+	}
+	vs, err := tss.sess.State.VoiceState(tss.guildID, member.User.ID)
+	return err == nil && vs.ChannelID == tss.channelID
 }
 
 func (tss *tsSession) Running() bool {
@@ -140,10 +282,25 @@ func (tss *tsSession) Running() bool {
 	return tss.isRunning
 }
 
+// resetTicker starts a fresh turn. The caller must hold tss.mu
 func (tss *tsSession) resetTicker() {
+	tss.turnDeadline = time.Now().Add(tss.turnDuration)
+	tss.turnLeft = 0
+	tss.turnExtended = false
 	tss.ticker.Reset(tss.turnDuration)
 }
 
+// resumeTicker picks the turn back up where it was paused. The caller must hold tss.mu
+func (tss *tsSession) resumeTicker() {
+	if tss.turnLeft <= 0 {
+		tss.resetTicker()
+		return
+	}
+	tss.turnDeadline = time.Now().Add(tss.turnLeft)
+	tss.ticker.Reset(tss.turnLeft)
+	tss.turnLeft = 0
+}
+
 func (tss *tsSession) resetTimer() {
-	tss.staleTimer.Reset(15 * time.Minute)
+	tss.staleTimer.Reset(tss.idleTimeout)
 }
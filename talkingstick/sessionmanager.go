@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"github.com/jmoiron/sqlx"
 	"log/slog"
 	"sync"
 	"time"
@@ -13,19 +14,102 @@ type Action string
 
 var (
 	ActionTogglePlayPause Action = "ts_toggle_play_pause"
+	ActionPause           Action = "ts_pause"
+	ActionResume          Action = "ts_resume"
 	ActionSkipUser        Action = "ts_skip_user"
 	ActionQuitSession     Action = "ts_quit_session"
+	ActionRaiseHand       Action = "ts_raise_hand"
+	ActionLowerHand       Action = "ts_lower_hand"
+	ActionBumpHand        Action = "ts_bump_hand"
+	ActionIncreaseTurn    Action = "ts_increase_turn"
+	ActionDecreaseTurn    Action = "ts_decrease_turn"
+	ActionExtendTurn      Action = "ts_extend_turn"
+	ActionRepostPanel     Action = "ts_repost_panel"
+	ActionStatus          Action = "ts_status"
+	ActionOrder           Action = "ts_order"
 )
 
+// Mode determines who the talking stick gets passed to
+type Mode string
+
+var (
+	// ModeRoundRobin passes the talking stick around the channel in order
+	ModeRoundRobin Mode = "round-robin"
+	// ModeQueue passes the talking stick to whoever raised their hand first
+	ModeQueue Mode = "queue"
+	// ModeDebate alternates the talking stick between two teams over the phases of a debate
+	ModeDebate Mode = "debate"
+)
+
+// controlActions change how the session runs, in a moderated debate only the moderator can perform them
+var controlActions = map[Action]bool{
+	ActionTogglePlayPause: true,
+	ActionPause:           true,
+	ActionResume:          true,
+	ActionSkipUser:        true,
+	ActionQuitSession:     true,
+	ActionIncreaseTurn:    true,
+	ActionDecreaseTurn:    true,
+}
+
+// handActions only affect the member's own place in the raise hand queue
+var handActions = map[Action]bool{
+	ActionRaiseHand: true,
+	ActionLowerHand: true,
+}
+
+// ActionRequest is a request to perform an action on the talking stick session in a channel
+type ActionRequest struct {
+	ChannelID string
+	Action    Action
+	// Member that requested the action
+	Member *discordgo.Member
+	// Values selected in a select menu, if any
+	Values []string
+}
+
 var ErrSessionExists = errors.New("a talking stick session already exists")
 var ErrSessionNotFound = errors.New("channel has no active talking stick session")
 var ErrUnknownAction = errors.New("unknown action")
+var ErrNotModerator = errors.New("only moderators can perform this action")
+var ErrNotAuthorized = errors.New("only participants, the session creator, and moderators can control the session")
+var ErrAlreadyPaused = errors.New("the session is already paused")
+var ErrNotPaused = errors.New("the session isn't paused")
+
+// SessionConfig describes how a talking stick session should be run
+type SessionConfig struct {
+	// TurnDuration is how long each member holds the talking stick
+	TurnDuration time.Duration
+	// InactivityTimeout is how long the session can go without any interaction before it is closed
+	InactivityTimeout time.Duration
+	// PanelChannelID is where the control panel is posted, defaults to the voice channel's chat
+	PanelChannelID string
+	// Strict server mutes everyone in the voice channel except the stickholder
+	Strict bool
+	// Mode determines who the talking stick gets passed to, defaults to ModeRoundRobin
+	Mode Mode
+	// Order determines the order members hold the talking stick in, defaults to OrderRandom
+	Order OrderStrategy
+	// Skip determines which members are passed over in round-robin mode
+	Skip SkipRules
+	// Debate configures the phases of a debate, only used in ModeDebate
+	Debate DebateConfig
+}
 
 type SessionManager interface {
-	// Create a new talking stick session
-	Create(guildID, channelID string, duration time.Duration) error
-	// Handle a request action
-	Handle(channelID string, action Action) error
+	// Create a new talking stick session on behalf of the creator
+	Create(guildID, channelID, creatorID string, cfg SessionConfig) error
+	// Handle a request action. Actions that report on the session return an embed describing it
+	Handle(req ActionRequest) (*discordgo.MessageEmbed, error)
+	// Stats returns the all-time talking stick stats of the guild's members
+	Stats(guildID string) ([]MemberStats, error)
+	// Settings returns the guild's talking stick defaults
+	Settings(guildID string) (GuildSettings, error)
+	// UpdateSettings replaces the guild's talking stick defaults and returns the settings that were saved, the turn
+	// duration is clamped to the range sessions allow
+	UpdateSettings(guildID string, settings GuildSettings) (GuildSettings, error)
+	// HandleVoiceStateUpdate keeps sessions in sync with members joining and leaving voice channels
+	HandleVoiceStateUpdate(s *discordgo.Session, e *discordgo.VoiceStateUpdate)
 	// Close all running sessions. Blocks until all sessions are finished closing
 	Close() error
 }
@@ -34,31 +118,71 @@ type SessManager struct {
 	mu         *sync.Mutex
 	wg         *sync.WaitGroup
 	sess       *discordgo.Session
+	db         *sqlx.DB
+	mutes      *muteLedger
+	joins      *joinTracker
 	tsSessions map[string]*tsSession
 }
 
-func NewSessionManager(s *discordgo.Session) SessionManager {
-	return &SessManager{
+func NewSessionManager(s *discordgo.Session, db *sqlx.DB) SessionManager {
+	manager := &SessManager{
 		sess:       s,
+		db:         db,
 		mu:         &sync.Mutex{},
 		wg:         &sync.WaitGroup{},
+		mutes:      newMuteLedger(s, db),
+		joins:      newJoinTracker(),
 		tsSessions: make(map[string]*tsSession),
 	}
+
+	// sessions don't survive a restart, give back the voice of anyone they left muted
+	manager.wg.Add(1)
+	go func() {
+		defer manager.wg.Done()
+		manager.mutes.RestoreLeftover()
+	}()
+	return manager
 }
 
-func (s *SessManager) Create(guildID, channelID string, duration time.Duration) error {
+func (s *SessManager) Create(guildID, channelID, creatorID string, cfg SessionConfig) error {
 	// check if a session already exists
 	if tss := s.getSession(channelID); tss != nil {
 		return ErrSessionExists
 	}
 
+	if cfg.Mode == "" {
+		cfg.Mode = ModeRoundRobin
+	}
+	if cfg.InactivityTimeout <= 0 {
+		cfg.InactivityTimeout = defaultGuildSettings().InactivityTimeout
+	}
+	if cfg.PanelChannelID == "" {
+		cfg.PanelChannelID = channelID
+	}
+
 	// load the voice channels members
 	members := loadVoiceMembers(s.sess, guildID, channelID)
-	shuffleDGMembers(members)
+	if cfg.Mode == ModeDebate {
+		members = removeMember(members, cfg.Debate.ModeratorID) // the moderator doesn't debate
+	}
+	s.orderMembers(guildID, members, cfg.Order)
 	head := newMemberList(members)
 
 	// create a new session
-	tss := newTSSession(s.sess, channelID, duration, head)
+	cfg.TurnDuration = clampTurnDuration(cfg.TurnDuration)
+	var d *debate
+	if cfg.Mode == ModeDebate {
+		cfg.Debate.Opening = clampTurnDuration(orDefault(cfg.Debate.Opening, cfg.TurnDuration))
+		cfg.Debate.Rebuttal = clampTurnDuration(orDefault(cfg.Debate.Rebuttal, cfg.TurnDuration))
+		cfg.Debate.Closing = clampTurnDuration(orDefault(cfg.Debate.Closing, cfg.TurnDuration))
+		var err error
+		if d, err = newDebate(head, cfg.Debate, cfg.TurnDuration); err != nil {
+			return err
+		}
+		cfg.TurnDuration = d.currentPhase().duration
+	}
+	tss := newTSSession(s.sess, s.db, guildID, channelID, creatorID, cfg, head, s.mutes)
+	tss.debate = d
 	if err := tss.CreateControlPanel(); err != nil {
 		return fmt.Errorf("failed to create control panel: %w", err)
 	}
@@ -67,28 +191,99 @@ func (s *SessManager) Create(guildID, channelID string, duration time.Duration)
 	return nil
 }
 
-func (s *SessManager) Handle(channelID string, action Action) error {
-	slog.Debug("received handle action request", "channel_id", channelID, "action", action)
+func (s *SessManager) Handle(req ActionRequest) (*discordgo.MessageEmbed, error) {
+	slog.Debug("received handle action request", "channel_id", req.ChannelID, "action", req.Action)
 
-	tss := s.getSession(channelID)
+	tss := s.getSession(req.ChannelID)
 	if tss == nil {
-		return ErrSessionNotFound
+		return nil, ErrSessionNotFound
+	}
+
+	// reporting on the session is open to everyone and doesn't count as activity
+	reports := map[Action]func() *discordgo.MessageEmbed{
+		ActionStatus: func() *discordgo.MessageEmbed { return getEmbed(tss) },
+		ActionOrder:  tss.OrderEmbed,
+	}
+	if report, ok := reports[req.Action]; ok {
+		return report(), nil
+	}
+
+	// anyone in the channel can queue up to speak, everything else is limited to the session's participants
+	if handActions[req.Action] {
+		if !tss.inChannel(req.Member) && !tss.isAuthorized(req.Member) {
+			return nil, ErrNotAuthorized
+		}
+	} else if !tss.isAuthorized(req.Member) {
+		return nil, ErrNotAuthorized
+	}
+	if controlActions[req.Action] && !tss.isDebateModerator(req.Member.User.ID) && !isModerator(req.Member) {
+		return nil, ErrNotDebateModerator
 	}
 	defer tss.resetTimer()
 
-	actions := map[Action]func(){
-		ActionQuitSession:     tss.Quit,
-		ActionSkipUser:        func() { tss.Pass(nil) },
-		ActionTogglePlayPause: s.togglePlayPauseHandler(tss),
+	actions := map[Action]func() error{
+		ActionQuitSession:     noError(tss.Quit),
+		ActionSkipUser:        noError(tss.Skip),
+		ActionTogglePlayPause: noError(s.togglePlayPauseHandler(tss)),
+		ActionPause:           s.pauseHandler(tss),
+		ActionResume:          s.resumeHandler(tss),
+		ActionRaiseHand:       func() error { return tss.RaiseHand(req.Member) },
+		ActionLowerHand:       func() error { return tss.LowerHand(req.Member.User.ID) },
+		ActionBumpHand:        s.bumpHandHandler(tss, req),
+		ActionIncreaseTurn:    noError(func() { tss.AdjustTurnDuration(turnAdjustment) }),
+		ActionDecreaseTurn:    noError(func() { tss.AdjustTurnDuration(-turnAdjustment) }),
+		ActionExtendTurn:      func() error { return tss.ExtendTurn(req.Member.User.ID) },
+		ActionRepostPanel:     tss.RepostControlPanel,
 	}
 
-	handler, ok := actions[action]
+	handler, ok := actions[req.Action]
 	if !ok {
-		return fmt.Errorf("%w: %s", ErrUnknownAction, action)
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAction, req.Action)
 	}
+	return nil, handler()
+}
 
-	handler()
-	return nil
+func (s *SessManager) Stats(guildID string) ([]MemberStats, error) {
+	return loadGuildStats(s.db, guildID)
+}
+
+func (s *SessManager) Settings(guildID string) (GuildSettings, error) {
+	return loadGuildSettings(s.db, guildID)
+}
+
+func (s *SessManager) UpdateSettings(guildID string, settings GuildSettings) (GuildSettings, error) {
+	settings.TurnDuration = clampTurnDuration(settings.TurnDuration)
+	if err := saveGuildSettings(s.db, guildID, settings); err != nil {
+		return GuildSettings{}, err
+	}
+	return settings, nil
+}
+
+func (s *SessManager) HandleVoiceStateUpdate(_ *discordgo.Session, e *discordgo.VoiceStateUpdate) {
+	s.joins.Update(e)
+	if e.ChannelID != "" {
+		s.mutes.RetryPending(e.GuildID, e.UserID)
+	}
+
+	var previousChannelID string
+	if e.BeforeUpdate != nil {
+		previousChannelID = e.BeforeUpdate.ChannelID
+	}
+	if previousChannelID == e.ChannelID {
+		return // mute, deafen, etc. we only care about members moving between channels
+	}
+
+	// members leaving a strict session get their voice back
+	if tss := s.getSession(previousChannelID); tss != nil && tss.strict {
+		slog.Debug("member left strict TS session", "channel_id", previousChannelID, "user_id", e.UserID)
+		tss.forgetMember(e.UserID)
+	}
+
+	// members joining a strict session have to wait their turn
+	if tss := s.getSession(e.ChannelID); tss != nil && tss.strict && !tss.isStickholder(e.UserID) {
+		slog.Debug("member joined strict TS session", "channel_id", e.ChannelID, "user_id", e.UserID)
+		tss.muteMember(e.UserID)
+	}
 }
 
 func (s *SessManager) Close() error {
@@ -109,6 +304,43 @@ func (s *SessManager) togglePlayPauseHandler(tss *tsSession) func() {
 		tss.RefreshControlPanel()
 	}
 }
+
+func (s *SessManager) pauseHandler(tss *tsSession) func() error {
+	return func() error {
+		if !tss.Running() {
+			return ErrAlreadyPaused
+		}
+		tss.Pause()
+		tss.RefreshControlPanel()
+		return nil
+	}
+}
+
+func (s *SessManager) resumeHandler(tss *tsSession) func() error {
+	return func() error {
+		if tss.Running() {
+			return ErrNotPaused
+		}
+		tss.Play()
+		tss.RefreshControlPanel()
+		return nil
+	}
+}
+
+func (s *SessManager) bumpHandHandler(tss *tsSession, req ActionRequest) func() error {
+	return func() error {
+		if !isModerator(req.Member) {
+			return ErrNotModerator
+		}
+		for _, userID := range req.Values {
+			if err := tss.BumpHand(userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 func (s *SessManager) getSession(channelID string) *tsSession {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -117,6 +349,12 @@ func (s *SessManager) getSession(channelID string) *tsSession {
 
 func (s *SessManager) launch(tss *tsSession) {
 	defer s.unregister(tss.channelID)
+	defer func() {
+		// the session has already been closed by the time we get here, don't take the whole bot down with it
+		if r := recover(); r != nil {
+			slog.Error("talking stick session crashed", "channel_id", tss.channelID, "panic", r)
+		}
+	}()
 	tss.Start()
 }
 
@@ -141,3 +379,19 @@ func (s *SessManager) unregister(channelID string) {
 	delete(s.tsSessions, channelID)
 	s.wg.Done()
 }
+
+func noError(fn func()) func() error {
+	return func() error {
+		fn()
+		return nil
+	}
+}
+
+// isModerator reports whether the member is allowed to manage other members in the voice channel
+func isModerator(member *discordgo.Member) bool {
+	if member == nil {
+		return false
+	}
+	perms := discordgo.PermissionAdministrator | discordgo.PermissionManageChannels | discordgo.PermissionVoiceMuteMembers
+	return member.Permissions&int64(perms) != 0
+}
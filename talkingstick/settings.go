@@ -0,0 +1,95 @@
+package talkingstick
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// GuildSettings are the defaults used for talking stick sessions started in a guild
+type GuildSettings struct {
+	TurnDuration      time.Duration
+	InactivityTimeout time.Duration
+	Order             OrderStrategy
+	Strict            bool
+	// PanelChannelID is where control panels are posted, if empty they are posted in the voice channel's chat
+	PanelChannelID string
+}
+
+// SessionConfig returns the config for a session started with the guild's defaults
+func (gs GuildSettings) SessionConfig() SessionConfig {
+	return SessionConfig{
+		TurnDuration:      gs.TurnDuration,
+		InactivityTimeout: gs.InactivityTimeout,
+		Strict:            gs.Strict,
+		Order:             gs.Order,
+		PanelChannelID:    gs.PanelChannelID,
+	}
+}
+
+func defaultGuildSettings() GuildSettings {
+	return GuildSettings{
+		TurnDuration:      15 * time.Second,
+		InactivityTimeout: 15 * time.Minute,
+		Order:             OrderRandom,
+		Strict:            false,
+	}
+}
+
+type guildSettingsRow struct {
+	TurnDurationSeconds      int            `db:"turn_duration_seconds"`
+	InactivityTimeoutSeconds int            `db:"inactivity_timeout_seconds"`
+	OrderStrategy            string         `db:"order_strategy"`
+	Strict                   bool           `db:"strict"`
+	PanelChannelID           sql.NullString `db:"panel_channel_id"`
+}
+
+func loadGuildSettings(db *sqlx.DB, guildID string) (GuildSettings, error) {
+	var row guildSettingsRow
+	query := `SELECT turn_duration_seconds, inactivity_timeout_seconds, order_strategy, strict, panel_channel_id
+				FROM talking_stick_settings WHERE guild_id = $1`
+	if err := db.Get(&row, query, guildID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return defaultGuildSettings(), nil
+		}
+		return defaultGuildSettings(), fmt.Errorf("select: %w", err)
+	}
+
+	return GuildSettings{
+		TurnDuration:      time.Duration(row.TurnDurationSeconds) * time.Second,
+		InactivityTimeout: time.Duration(row.InactivityTimeoutSeconds) * time.Second,
+		Order:             OrderStrategy(row.OrderStrategy),
+		Strict:            row.Strict,
+		PanelChannelID:    row.PanelChannelID.String,
+	}, nil
+}
+
+func saveGuildSettings(db *sqlx.DB, guildID string, settings GuildSettings) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`INSERT INTO guilds (guild_id) VALUES ($1) ON CONFLICT DO NOTHING`, guildID); err != nil {
+		return fmt.Errorf("insert guild: %w", err)
+	}
+
+	query := `INSERT INTO talking_stick_settings
+				(guild_id, turn_duration_seconds, inactivity_timeout_seconds, order_strategy, strict, panel_channel_id)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				ON CONFLICT (guild_id) DO UPDATE SET
+					turn_duration_seconds = EXCLUDED.turn_duration_seconds,
+					inactivity_timeout_seconds = EXCLUDED.inactivity_timeout_seconds,
+					order_strategy = EXCLUDED.order_strategy,
+					strict = EXCLUDED.strict,
+					panel_channel_id = EXCLUDED.panel_channel_id`
+	panelChannelID := sql.NullString{String: settings.PanelChannelID, Valid: settings.PanelChannelID != ""}
+	if _, err = tx.Exec(query, guildID, int(settings.TurnDuration.Seconds()), int(settings.InactivityTimeout.Seconds()),
+		settings.Order, settings.Strict, panelChannelID); err != nil {
+		return fmt.Errorf("upsert settings: %w", err)
+	}
+	return tx.Commit()
+}
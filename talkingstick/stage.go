@@ -0,0 +1,80 @@
+package talkingstick
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"log/slog"
+)
+
+// isStageChannel reports whether the channel is a stage, where the stickholder is made a speaker instead of a
+// priority speaker
+func isStageChannel(s *discordgo.Session, channelID string) bool {
+	channel, err := s.State.Channel(channelID)
+	if err != nil {
+		if channel, err = s.Channel(channelID); err != nil {
+			slog.Error("failed to get channel", "channel_id", channelID, "error", err)
+			return false
+		}
+	}
+	return channel.Type == discordgo.ChannelTypeGuildStageVoice
+}
+
+// enforceStage moves every speaker to the audience and invites the stickholder to speak
+func (tss *tsSession) enforceStage() {
+	slog.Debug("moving stage speakers to the audience", "channel_id", tss.channelID)
+
+	guild, err := tss.sess.State.Guild(tss.guildID)
+	if err != nil {
+		slog.Error("failed to access guild state", "error", err)
+		return
+	}
+
+	holderID := tss.stickholder.data.User.ID
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID != tss.channelID || (vs.Suppress && vs.UserID != holderID) {
+			continue
+		}
+		tss.setSpeaker(vs.UserID, vs.UserID == holderID)
+	}
+}
+
+// setSpeaker moves the member between the stage speakers and the audience, remembering where they started
+func (tss *tsSession) setSpeaker(userID string, speaker bool) {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	if tss.closed {
+		return
+	}
+	if _, ok := tss.speakers[userID]; !ok {
+		vs, err := tss.sess.State.VoiceState(tss.guildID, userID)
+		tss.speakers[userID] = err == nil && !vs.Suppress
+	}
+
+	if err := updateStageVoiceState(tss.sess, tss.guildID, tss.channelID, userID, !speaker); err != nil {
+		slog.Error("failed to update stage voice state", "channel_id", tss.channelID, "user_id", userID, "error", err)
+	}
+}
+
+// restoreSpeakers puts everyone the session touched back where they started. The caller must hold tss.mu
+func (tss *tsSession) restoreSpeakers() {
+	for userID, speaker := range tss.speakers {
+		if err := updateStageVoiceState(tss.sess, tss.guildID, tss.channelID, userID, !speaker); err != nil {
+			slog.Warn("failed to restore stage voice state", "channel_id", tss.channelID, "user_id", userID, "error", err)
+		}
+		delete(tss.speakers, userID)
+	}
+}
+
+// updateStageVoiceState suppresses or un-suppresses a member in a stage channel, discordgo doesn't wrap this endpoint
+func updateStageVoiceState(s *discordgo.Session, guildID, channelID, userID string, suppress bool) error {
+	data := struct {
+		ChannelID string `json:"channel_id"`
+		Suppress  bool   `json:"suppress"`
+	}{channelID, suppress}
+
+	endpoint := discordgo.EndpointGuild(guildID) + "/voice-states/" + userID
+	if _, err := s.RequestWithBucketID("PATCH", endpoint, data, discordgo.EndpointGuild(guildID)+"/voice-states/"); err != nil {
+		return fmt.Errorf("patch voice state: %w", err)
+	}
+	return nil
+}
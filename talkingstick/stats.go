@@ -0,0 +1,140 @@
+package talkingstick
+
+import (
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// MemberStats is a summary of how a member used the talking stick
+type MemberStats struct {
+	UserID       string
+	TurnsTaken   int
+	TurnsSkipped int
+	TimeHeld     time.Duration
+	LastHeld     time.Time
+}
+
+func newSessionStats(head *tsMember) map[string]*MemberStats {
+	stats := make(map[string]*MemberStats)
+	if head == nil {
+		return stats
+	}
+
+	current := head
+	for {
+		stats[current.data.User.ID] = &MemberStats{UserID: current.data.User.ID}
+		current = current.next
+		if current == head {
+			return stats
+		}
+	}
+}
+
+// resumeTurn starts the clock on the stickholder's turn. The caller must hold tss.mu
+func (tss *tsSession) resumeTurn() {
+	if tss.turnStart.IsZero() {
+		tss.turnStart = time.Now()
+	}
+}
+
+// pauseTurn stops the clock on the stickholder's turn without ending it. The caller must hold tss.mu
+func (tss *tsSession) pauseTurn() {
+	if tss.turnStart.IsZero() {
+		return
+	}
+	tss.turnHeld += time.Since(tss.turnStart)
+	tss.turnStart = time.Time{}
+}
+
+// endTurn credits the stickholder with the time they held the stick. The caller must hold tss.mu
+func (tss *tsSession) endTurn(skipped bool) {
+	tss.pauseTurn()
+	held := tss.turnHeld
+	tss.turnHeld = 0
+	if held == 0 {
+		return // the stickholder never actually got to speak
+	}
+
+	userID := tss.stickholder.data.User.ID
+	stats, ok := tss.stats[userID]
+	if !ok {
+		stats = &MemberStats{UserID: userID}
+		tss.stats[userID] = stats
+	}
+	stats.TimeHeld += held
+	stats.LastHeld = time.Now()
+	if skipped {
+		stats.TurnsSkipped++
+	} else {
+		stats.TurnsTaken++
+	}
+}
+
+// saveStats stores the session and the stats of everyone that took part in it
+func (tss *tsSession) saveStats(db *sqlx.DB) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`INSERT INTO guilds (guild_id) VALUES ($1) ON CONFLICT DO NOTHING`, tss.guildID); err != nil {
+		return fmt.Errorf("insert guild: %w", err)
+	}
+
+	var sessionID int64
+	query := `INSERT INTO talking_stick_sessions (guild_id, channel_id, started_at, ended_at)
+				VALUES ($1, $2, $3, $4) RETURNING session_id`
+	if err = tx.Get(&sessionID, query, tss.guildID, tss.channelID, tss.startTime, time.Now()); err != nil {
+		return fmt.Errorf("insert session: %w", err)
+	}
+
+	query = `INSERT INTO talking_stick_participants (session_id, user_id, turns_taken, turns_skipped, time_held_ms, last_held_at)
+				VALUES ($1, $2, $3, $4, $5, $6)`
+	for _, stats := range tss.stats {
+		var lastHeld *time.Time
+		if !stats.LastHeld.IsZero() {
+			lastHeld = &stats.LastHeld
+		}
+		if _, err = tx.Exec(query, sessionID, stats.UserID, stats.TurnsTaken, stats.TurnsSkipped,
+			stats.TimeHeld.Milliseconds(), lastHeld); err != nil {
+			return fmt.Errorf("insert participant: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// loadGuildStats sums up the stats of every session that took place in the guild
+func loadGuildStats(db *sqlx.DB, guildID string) ([]MemberStats, error) {
+	var rows []struct {
+		UserID       string `db:"user_id"`
+		TurnsTaken   int    `db:"turns_taken"`
+		TurnsSkipped int    `db:"turns_skipped"`
+		TimeHeldMS   int64  `db:"time_held_ms"`
+	}
+	query := `SELECT p.user_id,
+				SUM(p.turns_taken)   AS turns_taken,
+				SUM(p.turns_skipped) AS turns_skipped,
+				SUM(p.time_held_ms)  AS time_held_ms
+			FROM talking_stick_participants p
+			JOIN talking_stick_sessions s ON s.session_id = p.session_id
+			WHERE s.guild_id = $1
+			GROUP BY p.user_id
+			ORDER BY time_held_ms DESC
+			LIMIT 25`
+	if err := db.Select(&rows, query, guildID); err != nil {
+		return nil, fmt.Errorf("select: %w", err)
+	}
+
+	stats := make([]MemberStats, len(rows))
+	for i, row := range rows {
+		stats[i] = MemberStats{
+			UserID:       row.UserID,
+			TurnsTaken:   row.TurnsTaken,
+			TurnsSkipped: row.TurnsSkipped,
+			TimeHeld:     time.Duration(row.TimeHeldMS) * time.Millisecond,
+		}
+	}
+	return stats, nil
+}
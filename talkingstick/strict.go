@@ -0,0 +1,89 @@
+package talkingstick
+
+import (
+	"log/slog"
+)
+
+// enforceStrictMode server mutes everyone in the voice channel except the stickholder
+func (tss *tsSession) enforceStrictMode() {
+	slog.Debug("enforcing strict mode", "channel_id", tss.channelID)
+
+	guild, err := tss.sess.State.Guild(tss.guildID)
+	if err != nil {
+		slog.Error("failed to access guild state", "error", err)
+		return
+	}
+
+	holderID := tss.stickholder.data.User.ID
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID != tss.channelID {
+			continue
+		}
+		if vs.UserID == holderID {
+			tss.releaseMember(vs.UserID)
+		} else {
+			tss.muteMember(vs.UserID)
+		}
+	}
+}
+
+// muteMember server mutes the member and marks them as one this session is responsible for restoring. The lock is
+// held throughout so a closing session restores the member after the mute, never before it
+func (tss *tsSession) muteMember(userID string) {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	if tss.closed {
+		return
+	}
+	tss.muted[userID] = true
+
+	if err := tss.mutes.Mute(tss.guildID, userID); err != nil {
+		slog.Error("failed to mute member", "channel_id", tss.channelID, "user_id", userID, "error", err)
+	}
+}
+
+// releaseMember lets the member speak while they hold the stick
+func (tss *tsSession) releaseMember(userID string) {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	if tss.closed {
+		return
+	}
+	tss.muted[userID] = true
+
+	if err := tss.mutes.Release(tss.guildID, userID); err != nil {
+		slog.Error("failed to unmute member", "channel_id", tss.channelID, "user_id", userID, "error", err)
+	}
+}
+
+// forgetMember restores the member's original mute state, used when they leave the channel mid-session
+func (tss *tsSession) forgetMember(userID string) {
+	tss.mu.Lock()
+	_, ok := tss.muted[userID]
+	delete(tss.muted, userID)
+	tss.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if err := tss.mutes.Restore(tss.guildID, userID); err != nil {
+		slog.Warn("failed to restore mute state, will retry when they reconnect", "user_id", userID, "error", err)
+	}
+}
+
+// restoreMutes sets every member the session touched back to their original mute state. The caller must hold tss.mu
+func (tss *tsSession) restoreMutes() {
+	for userID := range tss.muted {
+		if err := tss.mutes.Restore(tss.guildID, userID); err != nil {
+			slog.Warn("failed to restore mute state, will retry when they reconnect", "user_id", userID, "error", err)
+		}
+		delete(tss.muted, userID)
+	}
+}
+
+// isStickholder reports whether the user currently holds the talking stick
+func (tss *tsSession) isStickholder(userID string) bool {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	return tss.stickholder.data.User.ID == userID
+}
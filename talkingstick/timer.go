@@ -0,0 +1,95 @@
+package talkingstick
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	minTurnDuration = 5 * time.Second
+	maxTurnDuration = 30 * time.Minute
+	turnAdjustment  = 15 * time.Second
+	turnExtension   = 15 * time.Second
+)
+
+var ErrNotStickholder = errors.New("only the stickholder can perform this action")
+var ErrAlreadyExtended = errors.New("the turn has already been extended")
+
+// AdjustTurnDuration changes how long each turn lasts, the current turn is adjusted by the same amount. In a debate
+// only the turns of the current phase change, the next phase starts with its own duration
+func (tss *tsSession) AdjustTurnDuration(delta time.Duration) {
+	tss.mu.Lock()
+	duration := clampTurnDuration(tss.turnDuration + delta)
+	delta = duration - tss.turnDuration
+	slog.Debug("adjusting turn duration", "channel_id", tss.channelID, "duration", duration)
+	tss.turnDuration = duration
+	if tss.debate != nil {
+		tss.debate.setPhaseDuration(duration)
+	}
+	tss.addTurnTime(delta)
+	tss.mu.Unlock()
+
+	tss.RefreshControlPanel()
+}
+
+// ExtendTurn gives the stickholder a little more time, once per turn
+func (tss *tsSession) ExtendTurn(userID string) error {
+	tss.mu.Lock()
+	if tss.stickholder.data.User.ID != userID {
+		tss.mu.Unlock()
+		return ErrNotStickholder
+	}
+	if tss.turnExtended {
+		tss.mu.Unlock()
+		return ErrAlreadyExtended
+	}
+	slog.Debug("extending turn", "channel_id", tss.channelID, "user_id", userID)
+	tss.turnExtended = true
+	tss.addTurnTime(turnExtension)
+	tss.mu.Unlock()
+
+	tss.RefreshControlPanel()
+	return nil
+}
+
+// addTurnTime moves the end of the current turn, without letting it end sooner than a second from now.
+// The caller must hold tss.mu
+func (tss *tsSession) addTurnTime(delta time.Duration) {
+	if !tss.isRunning {
+		if tss.turnLeft > 0 {
+			tss.turnLeft = max(tss.turnLeft+delta, time.Second)
+		}
+		return
+	}
+	tss.turnDeadline = tss.turnDeadline.Add(delta)
+	if remaining := time.Until(tss.turnDeadline); remaining < time.Second {
+		tss.turnDeadline = time.Now().Add(time.Second)
+	}
+	tss.ticker.Reset(time.Until(tss.turnDeadline))
+}
+
+// turnStatus describes how much time is left on the current turn
+func (tss *tsSession) turnStatus() string {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	if tss.isRunning {
+		// discord renders relative timestamps as a live countdown, no need to keep editing the panel
+		return fmt.Sprintf("Ends <t:%d:R>", tss.turnDeadline.Unix())
+	}
+	if tss.turnLeft > 0 {
+		return fmt.Sprintf("Paused with %s left", tss.turnLeft.Round(time.Second))
+	}
+	return "Waiting to start"
+}
+
+func clampTurnDuration(d time.Duration) time.Duration {
+	return min(max(d, minTurnDuration), maxTurnDuration)
+}
+
+func (tss *tsSession) getTurnDuration() time.Duration {
+	tss.mu.Lock()
+	defer tss.mu.Unlock()
+	return tss.turnDuration
+}
@@ -0,0 +1,143 @@
+package youtube
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleRetention is how long expired results are kept around to fall back on when the quota runs out
+const staleRetention = 24 * time.Hour
+
+type cachedResults struct {
+	results YTSearchResults
+	expires time.Time
+}
+
+// CachedSearch caches the results of another SearchProvider. Results are kept in memory and, when a database is
+// given, in postgres so they survive restarts. Expired results are still served when the quota is exhausted
+type CachedSearch struct {
+	mu      *sync.Mutex
+	next    SearchProvider
+	ttl     time.Duration
+	db      *sqlx.DB
+	entries map[string]cachedResults
+}
+
+func NewCachedSearch(next SearchProvider, ttl time.Duration, db *sqlx.DB) *CachedSearch {
+	return &CachedSearch{
+		mu:      &sync.Mutex{},
+		next:    next,
+		ttl:     ttl,
+		db:      db,
+		entries: make(map[string]cachedResults),
+	}
+}
+
+func (c *CachedSearch) Search(req SearchRequest) (YTSearchResults, error) {
+	key := searchCacheKey(req)
+	cached, ok := c.get(key)
+	if ok && time.Now().Before(cached.expires) {
+		slog.Debug("search cache hit", "key", key)
+		return cached.results, nil
+	}
+
+	results, err := c.next.Search(req)
+	if errors.Is(err, ErrQuotaExhausted) && ok {
+		slog.Info("search quota is exhausted, serving stale results", "key", key)
+		return cached.results, nil
+	}
+	if err != nil {
+		return YTSearchResults{}, err
+	}
+	c.put(key, cachedResults{results: results, expires: time.Now().Add(c.ttl)})
+	return results, nil
+}
+
+// get the cached results whether they've expired or not, falling back to postgres on a miss
+func (c *CachedSearch) get(key string) (cachedResults, bool) {
+	c.mu.Lock()
+	cached, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok || c.db == nil {
+		return cached, ok
+	}
+
+	var row struct {
+		Results   []byte    `db:"results"`
+		ExpiresAt time.Time `db:"expires_at"`
+	}
+	query := `SELECT results, expires_at FROM youtube_search_cache WHERE cache_key = $1`
+	if err := c.db.Get(&row, query, key); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			slog.Error("failed to get cached search results", "key", key, "error", err)
+		}
+		return cachedResults{}, false
+	}
+	if err := json.Unmarshal(row.Results, &cached.results); err != nil {
+		slog.Error("failed to decode cached search results", "key", key, "error", err)
+		return cachedResults{}, false
+	}
+	cached.expires = row.ExpiresAt
+
+	c.mu.Lock()
+	c.entries[key] = cached
+	c.mu.Unlock()
+	return cached, true
+}
+
+func (c *CachedSearch) put(key string, cached cachedResults) {
+	c.mu.Lock()
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires.Add(staleRetention)) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cached
+	c.mu.Unlock()
+
+	if c.db == nil {
+		return
+	}
+	if err := saveSearchResults(c.db, key, cached); err != nil {
+		slog.Error("failed to save cached search results", "key", key, "error", err)
+	}
+}
+
+func saveSearchResults(db *sqlx.DB, key string, cached cachedResults) error {
+	results, err := json.Marshal(cached.results)
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO youtube_search_cache (cache_key, results, expires_at) VALUES ($1, $2, $3)
+				ON CONFLICT (cache_key) DO UPDATE SET results = EXCLUDED.results, expires_at = EXCLUDED.expires_at`
+	if _, err = tx.Exec(query, key, results, cached.expires); err != nil {
+		return fmt.Errorf("upsert: %w", err)
+	}
+	query = `DELETE FROM youtube_search_cache WHERE expires_at < $1`
+	if _, err = tx.Exec(query, time.Now().Add(-staleRetention)); err != nil {
+		return fmt.Errorf("delete stale: %w", err)
+	}
+	return tx.Commit()
+}
+
+// searchCacheKey normalizes the query and filters so equivalent searches share results
+func searchCacheKey(req SearchRequest) string {
+	query := strings.Join(strings.Fields(strings.ToLower(req.Query)), " ")
+	return strings.Join([]string{query, req.Type, req.Duration, req.Uploaded, req.Order,
+		fmt.Sprint(req.MaxResults), req.SafeSearch, req.PageToken}, "|")
+}
@@ -0,0 +1,151 @@
+package youtube
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidClip = errors.New("invalid clip")
+var ErrClippingUnavailable = errors.New("clipping is unavailable, ffmpeg is not installed")
+
+// Clip is a range of a video to download, a zero End clips to the end of the video
+type Clip struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+func (c Clip) IsZero() bool {
+	return c.Start == 0 && c.End == 0
+}
+
+// validate checks the clip fits within the video and fills in a missing end
+func (c Clip) validate(duration time.Duration) (Clip, error) {
+	if c.End == 0 {
+		c.End = duration
+	}
+	if c.Start < 0 {
+		return c, fmt.Errorf("%w: the start can't be negative", ErrInvalidClip)
+	}
+	if c.Start >= c.End {
+		return c, fmt.Errorf("%w: the start must be before the end", ErrInvalidClip)
+	}
+	if duration > 0 && c.End > duration {
+		return c, fmt.Errorf("%w: the video is only %s long", ErrInvalidClip, duration)
+	}
+	return c, nil
+}
+
+// ParseTimestamp parses timestamps like 83, 1:23, 1:02:03 and 1h2m3s
+func ParseTimestamp(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	var d time.Duration
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("%w: %q is not a timestamp", ErrInvalidClip, s)
+	}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%w: %q is not a timestamp", ErrInvalidClip, s)
+		}
+		d = d*60 + time.Duration(n)*time.Second
+	}
+	return d, nil
+}
+
+// FormatTimestamp formats the duration the way youtube shows timestamps, ie. 1:02:03
+func FormatTimestamp(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, sec := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, sec)
+	}
+	return fmt.Sprintf("%d:%02d", m, sec)
+}
+
+// ffmpeg output for each kind of clip, both are streamable so they can be written to a pipe
+var clipOutputs = map[Media]struct {
+	args        []string
+	contentType string
+	ext         string
+}{
+	MediaVideo: {
+		args:        []string{"-c:v", "libx264", "-preset", "veryfast", "-c:a", "aac", "-movflags", "frag_keyframe+empty_moov", "-f", "mp4"},
+		contentType: "video/mp4",
+		ext:         ".mp4",
+	},
+	MediaAudio: {
+		args:        []string{"-vn", "-c:a", "libmp3lame", "-q:a", "2", "-f", "mp3"},
+		contentType: "audio/mpeg",
+		ext:         ".mp3",
+	},
+}
+
+// clipReader reads the trimmed output of ffmpeg, closing it stops ffmpeg and the source stream
+type clipReader struct {
+	io.ReadCloser
+	cmd    *exec.Cmd
+	src    io.ReadCloser
+	stderr *bytes.Buffer
+}
+
+// Close stops ffmpeg, a failed run only ends the output early so its error is returned to tell a truncated clip
+// from a complete one
+func (r *clipReader) Close() error {
+	_ = r.ReadCloser.Close()
+	_ = r.src.Close()
+	if err := r.cmd.Wait(); err != nil {
+		slog.Error("ffmpeg exited with an error", "error", err, "output", lastLine(r.stderr.String()))
+		return fmt.Errorf("ffmpeg: %w", err)
+	}
+	return nil
+}
+
+// clipStream trims the stream with ffmpeg, re-encoding so the clip starts exactly where it was requested
+func clipStream(src io.ReadCloser, clip Clip, media Media) (io.ReadCloser, string, string, error) {
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, "", "", ErrClippingUnavailable
+	}
+
+	output := clipOutputs[media]
+	args := []string{"-hide_banner", "-loglevel", "error",
+		"-ss", formatSeconds(clip.Start), "-i", "pipe:0", "-t", formatSeconds(clip.End - clip.Start)}
+	args = append(append(args, output.args...), "pipe:1")
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = src
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("ffmpeg stdout: %w", err)
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, "", "", fmt.Errorf("start ffmpeg: %w", err)
+	}
+	return &clipReader{ReadCloser: stdout, cmd: cmd, src: src, stderr: stderr}, output.contentType, output.ext, nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i != -1 {
+		return s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package youtube
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"github.com/kkdai/youtube/v2"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type cachedDownload struct {
+	key         string
+	path        string
+	name        string
+	contentType string
+	quality     string
+	size        int64
+}
+
+// DownloadCache keeps finished downloads on disk so popular videos and clips aren't downloaded again. The least
+// recently used downloads are removed when the size limit is reached
+type DownloadCache struct {
+	mu      *sync.Mutex
+	dir     string
+	limit   int64
+	used    int64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+// downloadFilePrefix starts the name of every file the cache writes, so only those are cleaned up
+const downloadFilePrefix = "overlord-download-"
+
+// NewDownloadCache creates a cache that takes up at most limit bytes in dir. Downloads left behind by a previous
+// run aren't indexed and are removed
+func NewDownloadCache(dir string, limit int64) (*DownloadCache, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "overlord-downloads")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create directory: %w", err)
+	}
+	if err := removeDownloads(dir); err != nil {
+		return nil, fmt.Errorf("clear directory: %w", err)
+	}
+	return &DownloadCache{
+		mu:      &sync.Mutex{},
+		dir:     dir,
+		limit:   limit,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}, nil
+}
+
+// Get opens the cached download, ok is false when it isn't cached
+func (dc *DownloadCache) Get(key string) (*File, bool) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	elem, ok := dc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cachedDownload)
+	f, err := os.Open(entry.path)
+	if err != nil {
+		slog.Warn("failed to open cached download", "key", key, "error", err)
+		dc.remove(elem)
+		return nil, false
+	}
+	dc.lru.MoveToFront(elem)
+	return &File{
+		Name:         entry.name,
+		ContentType:  entry.contentType,
+		Quality:      entry.quality,
+		Size:         entry.size,
+		Cached:       true,
+		ReaderCloser: f,
+	}, true
+}
+
+// Wrap returns a copy of the file that's added to the cache once it has been read in full and closed
+func (dc *DownloadCache) Wrap(key string, file *File) *File {
+	tmp, err := os.CreateTemp(dc.dir, downloadFilePrefix+"*")
+	if err != nil {
+		slog.Warn("failed to create cache file", "key", key, "error", err)
+		return file
+	}
+	wrapped := *file
+	wrapped.ReaderCloser = &cacheWriter{
+		src:   file.ReaderCloser,
+		tmp:   tmp,
+		cache: dc,
+		entry: &cachedDownload{
+			key:         key,
+			name:        file.Name,
+			contentType: file.ContentType,
+			quality:     file.Quality,
+		},
+	}
+	return &wrapped
+}
+
+// add moves the finished download into the cache, making room for it by removing the least recently used downloads
+func (dc *DownloadCache) add(entry *cachedDownload, tmpPath string) {
+	if entry.size > dc.limit {
+		_ = os.Remove(tmpPath)
+		return
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	// another request may have cached the same download in the meantime
+	if elem, ok := dc.entries[entry.key]; ok {
+		dc.remove(elem)
+	}
+	for dc.used+entry.size > dc.limit && dc.lru.Len() > 0 {
+		dc.remove(dc.lru.Back())
+	}
+
+	entry.path = tmpPath
+	dc.entries[entry.key] = dc.lru.PushFront(entry)
+	dc.used += entry.size
+	slog.Debug("cached download", "key", entry.key, "size", entry.size, "used", dc.used)
+}
+
+// remove deletes the download from disk. The caller must hold dc.mu
+func (dc *DownloadCache) remove(elem *list.Element) {
+	entry := dc.lru.Remove(elem).(*cachedDownload)
+	// readers that already opened the file can finish, it's only unlinked
+	if err := os.Remove(entry.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Error("failed to remove cached download", "key", entry.key, "error", err)
+	}
+	delete(dc.entries, entry.key)
+	dc.used -= entry.size
+}
+
+// downloadCacheKey identifies a download by the video, the stream picked for it and the clip range
+func downloadCacheKey(videoID string, format *youtube.Format, opts DownloadOptions) string {
+	return fmt.Sprintf("%s:%d:%s:%d-%d", videoID, format.ItagNo, opts.Media,
+		opts.Clip.Start.Milliseconds(), opts.Clip.End.Milliseconds())
+}
+
+// Close removes all cached downloads
+func (dc *DownloadCache) Close() error {
+	return removeDownloads(dc.dir)
+}
+
+// removeDownloads deletes every cached or partial download in the directory, leaving anything else in it alone
+func removeDownloads(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasPrefix(entry.Name(), downloadFilePrefix) {
+			if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cacheWriter copies everything read from the stream to a temporary file, the file is only cached when the stream
+// was read to the end and closed without errors
+type cacheWriter struct {
+	src      io.ReadCloser
+	tmp      *os.File
+	cache    *DownloadCache
+	entry    *cachedDownload
+	complete bool
+	failed   bool
+}
+
+func (cw *cacheWriter) Read(p []byte) (int, error) {
+	n, err := cw.src.Read(p)
+	if n > 0 && !cw.failed {
+		if _, wErr := cw.tmp.Write(p[:n]); wErr != nil {
+			slog.Warn("failed to write cache file", "key", cw.entry.key, "error", wErr)
+			cw.failed = true
+		}
+		cw.entry.size += int64(n)
+	}
+	if errors.Is(err, io.EOF) {
+		cw.complete = true
+	} else if err != nil {
+		cw.failed = true
+	}
+	return n, err
+}
+
+func (cw *cacheWriter) Close() error {
+	// a stream can end early without a read error, ie. when ffmpeg fails, closing it reports the failure
+	err := cw.src.Close()
+	if closeErr := cw.tmp.Close(); err != nil || closeErr != nil {
+		cw.failed = true
+	}
+	if !cw.complete || cw.failed {
+		_ = os.Remove(cw.tmp.Name())
+		return err
+	}
+	cw.cache.add(cw.entry, cw.tmp.Name())
+	return err
+}
@@ -0,0 +1,120 @@
+package youtube
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingCloser reads its content in full but fails to close, like ffmpeg exiting with an error
+type failingCloser struct {
+	io.Reader
+}
+
+func (failingCloser) Close() error {
+	return errors.New("exit status 1")
+}
+
+func newTestCache(t *testing.T, limit int64) *DownloadCache {
+	t.Helper()
+	dc, err := NewDownloadCache(t.TempDir(), limit)
+	if err != nil {
+		t.Fatalf("NewDownloadCache() unexpected error: %v", err)
+	}
+	return dc
+}
+
+// download reads the file through the cache like an upload would
+func download(t *testing.T, dc *DownloadCache, key string, src io.ReadCloser) {
+	t.Helper()
+	file := dc.Wrap(key, &File{Name: key + ".mp4", ReaderCloser: src})
+	if _, err := io.ReadAll(file.ReaderCloser); err != nil {
+		t.Fatalf("read %s: %v", key, err)
+	}
+	_ = file.ReaderCloser.Close()
+}
+
+func TestDownloadCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dc := newTestCache(t, 10)
+	download(t, dc, "a", io.NopCloser(strings.NewReader("12345")))
+	download(t, dc, "b", io.NopCloser(strings.NewReader("1234")))
+	if _, ok := dc.Get("a"); !ok {
+		t.Fatal("a isn't cached")
+	}
+
+	download(t, dc, "c", io.NopCloser(strings.NewReader("123")))
+	if _, ok := dc.Get("b"); ok {
+		t.Error("b is still cached, want it evicted as the least recently used")
+	}
+	file, ok := dc.Get("a")
+	if !ok {
+		t.Fatal("a was evicted, want it kept as recently used")
+	}
+	content, _ := io.ReadAll(file.ReaderCloser)
+	_ = file.ReaderCloser.Close()
+	if string(content) != "12345" || !file.Cached || file.Size != 5 {
+		t.Errorf("cached a = %q, cached %v, size %d", content, file.Cached, file.Size)
+	}
+}
+
+func TestDownloadCacheSkipsIncompleteDownloads(t *testing.T) {
+	dc := newTestCache(t, 100)
+
+	// closed before it was read to the end
+	partial := dc.Wrap("partial", &File{ReaderCloser: io.NopCloser(strings.NewReader("12345"))})
+	_ = partial.ReaderCloser.Close()
+	if _, ok := dc.Get("partial"); ok {
+		t.Error("a partially read download was cached")
+	}
+
+	// read to the end, but the source failed
+	download(t, dc, "failed", failingCloser{strings.NewReader("12345")})
+	if _, ok := dc.Get("failed"); ok {
+		t.Error("a download whose source failed to close was cached")
+	}
+
+	// larger than the whole cache
+	download(t, dc, "large", io.NopCloser(strings.NewReader(strings.Repeat("1", 101))))
+	if _, ok := dc.Get("large"); ok {
+		t.Error("a download larger than the cache was cached")
+	}
+	if dc.used != 0 {
+		t.Errorf("cache uses %d bytes, want 0", dc.used)
+	}
+}
+
+func TestDownloadCacheKeepsUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	unrelated := filepath.Join(dir, "notes.txt")
+	leftover := filepath.Join(dir, downloadFilePrefix+"123")
+	for _, path := range []string{unrelated, leftover} {
+		if err := os.WriteFile(path, []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dc, err := NewDownloadCache(dir, 100)
+	if err != nil {
+		t.Fatalf("NewDownloadCache() unexpected error: %v", err)
+	}
+	if _, err = os.Stat(leftover); !os.IsNotExist(err) {
+		t.Error("a download left behind by a previous run wasn't removed")
+	}
+	if _, err = os.Stat(unrelated); err != nil {
+		t.Errorf("an unrelated file was removed: %v", err)
+	}
+
+	download(t, dc, "a", io.NopCloser(strings.NewReader("12345")))
+	if err = dc.Close(); err != nil {
+		t.Fatalf("Close() unexpected error: %v", err)
+	}
+	if _, ok := dc.Get("a"); ok {
+		t.Error("a cached download is still readable after close")
+	}
+	if _, err = os.Stat(unrelated); err != nil {
+		t.Errorf("an unrelated file was removed on close: %v", err)
+	}
+}
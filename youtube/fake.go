@@ -0,0 +1,107 @@
+package youtube
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FakeSearch is an in-memory SearchProvider. It also serves the Data API's search endpoint so it can stand in for
+// the real API behind a local server. Results are filtered by type and paged, other filters are ignored
+type FakeSearch struct {
+	mu      *sync.Mutex
+	results map[string]YTSearchResults
+	// Err is returned by every search when set
+	Err error
+	// Requests are the searches made so far
+	Requests []SearchRequest
+}
+
+func NewFakeSearch() *FakeSearch {
+	return &FakeSearch{
+		mu:       &sync.Mutex{},
+		results:  make(map[string]YTSearchResults),
+		Requests: make([]SearchRequest, 0),
+	}
+}
+
+// Add results for the query, queries are matched case-insensitively
+func (f *FakeSearch) Add(query string, items ...YTSearchItem) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := strings.ToLower(strings.TrimSpace(query))
+	results := f.results[key]
+	results.Items = append(results.Items, items...)
+	f.results[key] = results
+}
+
+func (f *FakeSearch) Search(req SearchRequest) (YTSearchResults, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Requests = append(f.Requests, req)
+	if f.Err != nil {
+		return YTSearchResults{}, f.Err
+	}
+
+	items := make([]YTSearchItem, 0)
+	for _, item := range f.results[strings.ToLower(strings.TrimSpace(req.Query))].Items {
+		if req.Type == "" || item.ID.Kind == "youtube#"+req.Type {
+			items = append(items, item)
+		}
+	}
+
+	// page tokens are the offset of the page
+	perPage := req.MaxResults
+	if perPage <= 0 {
+		perPage = 5
+	}
+	offset, _ := strconv.Atoi(req.PageToken)
+	offset = min(max(offset, 0), len(items))
+	end := min(offset+perPage, len(items))
+
+	results := YTSearchResults{Items: items[offset:end]}
+	results.PageInfo.TotalResults = len(items)
+	results.PageInfo.ResultsPerPage = perPage
+	if end < len(items) {
+		results.NextPageToken = strconv.Itoa(end)
+	}
+	if offset > 0 {
+		results.PrevPageToken = strconv.Itoa(max(offset-perPage, 0))
+	}
+	return results, nil
+}
+
+func (f *FakeSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	maxResults, _ := strconv.Atoi(params.Get("maxResults"))
+	results, err := f.Search(SearchRequest{
+		Query:      params.Get("q"),
+		Type:       params.Get("type"),
+		Duration:   params.Get("videoDuration"),
+		Order:      params.Get("order"),
+		MaxResults: maxResults,
+		SafeSearch: params.Get("safeSearch"),
+		PageToken:  params.Get("pageToken"),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(results); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// NewVideoResult builds a search result for a video, handy for filling a FakeSearch
+func NewVideoResult(videoID, title, channel string) YTSearchItem {
+	var item YTSearchItem
+	item.Kind = "youtube#searchResult"
+	item.ID.Kind = "youtube#video"
+	item.ID.VideoID = videoID
+	item.Snippet.Title = title
+	item.Snippet.ChannelTitle = channel
+	return item
+}
@@ -0,0 +1,186 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kkdai/youtube/v2"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Media is the kind of stream to download
+type Media string
+
+var (
+	// MediaVideo downloads a video stream that includes audio
+	MediaVideo Media = "video"
+	// MediaAudio downloads an audio only stream
+	MediaAudio Media = "audio"
+)
+
+// Audio qualities, video qualities are the resolution label of the stream (ie. 720p)
+const (
+	AudioQualityHigh   = "high"
+	AudioQualityMedium = "medium"
+	AudioQualityLow    = "low"
+)
+
+var ErrQualityUnavailable = errors.New("requested quality is unavailable")
+var ErrTooLarge = errors.New("video is too large to upload")
+
+// DownloadOptions describe which stream to download
+type DownloadOptions struct {
+	// Media is the kind of stream to download, defaults to MediaVideo
+	Media Media
+	// Quality of the stream, the best available quality is used when empty
+	Quality string
+	// MaxSize is the largest stream in bytes that can be uploaded, there is no limit when 0
+	MaxSize int64
+	// Clip trims the download to a range of the video, the whole video is downloaded when zero
+	Clip Clip
+}
+
+// extensions of the containers youtube serves, keyed by mime type
+var extensions = map[string]string{
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+	"video/3gpp": ".3gp",
+	"audio/mp4":  ".m4a",
+	"audio/webm": ".webm",
+}
+
+// selectFormats returns the formats matching the options, best first
+func selectFormats(formats youtube.FormatList, opts DownloadOptions) (youtube.FormatList, error) {
+	var candidates youtube.FormatList
+	switch opts.Media {
+	case MediaAudio:
+		candidates = formats.Type("audio/") // audio only formats
+	default:
+		candidates = formats.Type("video/").WithAudioChannels() // only get videos with audio
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no %s formats available", opts.Media)
+	}
+	candidates.Sort()
+
+	if opts.Quality == "" {
+		return candidates, nil
+	}
+	matches := candidates.Select(func(f youtube.Format) bool {
+		if opts.Media == MediaAudio {
+			return strings.EqualFold(f.AudioQuality, "AUDIO_QUALITY_"+opts.Quality)
+		}
+		return strings.HasPrefix(f.QualityLabel, opts.Quality)
+	})
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s, available qualities are %s", ErrQualityUnavailable, opts.Quality,
+			strings.Join(availableQualities(candidates, opts.Media), ", "))
+	}
+	return matches, nil
+}
+
+// pickFormat picks the best format matching the options that fits within the size limit. When none fit, lower
+// qualities and then audio only are tried, reporting that the format was downgraded
+func pickFormat(all youtube.FormatList, opts DownloadOptions) (*youtube.Format, bool, error) {
+	formats, err := selectFormats(all, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	if opts.MaxSize <= 0 {
+		return &formats[0], false, nil
+	}
+	format, unknown := firstFit(formats, opts.MaxSize)
+	if format != nil {
+		return format, false, nil
+	}
+
+	// fall back to smaller streams of the same media, then to audio only
+	requested := formatSize(&formats[0])
+	fallbacks, _ := selectFormats(all, DownloadOptions{Media: opts.Media})
+	fallbacks = fallbacks.Select(func(f youtube.Format) bool { return formatSize(&f) < requested })
+	if opts.Media != MediaAudio {
+		audio, _ := selectFormats(all, DownloadOptions{Media: MediaAudio})
+		fallbacks = append(fallbacks, audio...)
+	}
+	format, unknownFallback := firstFit(fallbacks, opts.MaxSize)
+	if format != nil {
+		return format, true, nil
+	}
+
+	// a stream of unknown size might still fit, it's only too large if every size is known
+	if unknown != nil {
+		return unknown, false, nil
+	}
+	if unknownFallback != nil {
+		return unknownFallback, true, nil
+	}
+	return nil, false, fmt.Errorf("%w: every format is larger than the %dMB upload limit", ErrTooLarge, opts.MaxSize/(1<<20))
+}
+
+// firstFit returns the first format known to be no larger than maxSize, and the first format of unknown size as a
+// fallback when none are
+func firstFit(formats youtube.FormatList, maxSize int64) (fit, unknown *youtube.Format) {
+	for i := range formats {
+		size := formatSize(&formats[i])
+		if size == 0 && unknown == nil {
+			unknown = &formats[i]
+		}
+		if size > 0 && size <= maxSize {
+			return &formats[i], unknown
+		}
+	}
+	return nil, unknown
+}
+
+// formatSize returns the size of the stream in bytes, estimating it from the bitrate when youtube doesn't report
+// it. Returns 0 if the size is unknown
+func formatSize(f *youtube.Format) int64 {
+	if f.ContentLength > 0 {
+		return f.ContentLength
+	}
+	durationMs, _ := strconv.ParseInt(f.ApproxDurationMs, 10, 64)
+	bitrate := f.AverageBitrate
+	if bitrate == 0 {
+		bitrate = f.Bitrate
+	}
+	return int64(bitrate) * durationMs / 8000
+}
+
+// availableQualities lists the distinct qualities of the formats
+func availableQualities(formats youtube.FormatList, media Media) []string {
+	seen := make(map[string]bool)
+	qualities := make([]string, 0, len(formats))
+	for _, f := range formats {
+		quality := f.QualityLabel
+		if media == MediaAudio {
+			quality = strings.ToLower(strings.TrimPrefix(f.AudioQuality, "AUDIO_QUALITY_"))
+		}
+		if quality != "" && !seen[quality] {
+			seen[quality] = true
+			qualities = append(qualities, quality)
+		}
+	}
+	return qualities
+}
+
+// getQuality describes the quality of the format, ie. 720p or medium audio
+func getQuality(f *youtube.Format) string {
+	if f.QualityLabel != "" {
+		return f.QualityLabel
+	}
+	return strings.ToLower(strings.TrimPrefix(f.AudioQuality, "AUDIO_QUALITY_")) + " audio"
+}
+
+// getContentType strips the codecs from the streams mime type and returns it along with the file extension
+func getContentType(mimeType string) (string, string) {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType, _, _ = strings.Cut(mimeType, ";")
+	}
+	ext, ok := extensions[mediaType]
+	if !ok {
+		ext = ".bin"
+	}
+	return mediaType, ext
+}
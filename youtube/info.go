@@ -0,0 +1,98 @@
+package youtube
+
+import (
+	"github.com/kkdai/youtube/v2"
+	"regexp"
+	"strings"
+)
+
+// chapterPattern matches description lines like "1:23 Intro", "(1:23) Intro" or "01:23 - Intro"
+var chapterPattern = regexp.MustCompile(`^\(?((?:\d+:)?\d{1,2}:\d{2})\)?\s*[-–:|]?\s*(.+)$`)
+
+// minChapters is the fewest timestamps youtube needs in a description before it splits the video into chapters
+const minChapters = 3
+
+// WatchURL links to the video on youtube
+func WatchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + videoID
+}
+
+func mapVideoInfo(video *youtube.Video) *VideoInfo {
+	info := &VideoInfo{
+		ID:          video.ID,
+		Title:       video.Title,
+		Author:      video.Author,
+		ChannelID:   video.ChannelID,
+		Duration:    video.Duration,
+		Views:       video.Views,
+		PublishDate: video.PublishDate,
+		Chapters:    parseChapters(video.Description),
+	}
+
+	// use the highest resolution thumbnail
+	var best uint
+	for _, thumbnail := range video.Thumbnails {
+		if size := thumbnail.Width * thumbnail.Height; size >= best {
+			best = size
+			info.ThumbnailURL = thumbnail.URL
+		}
+	}
+
+	for _, media := range []Media{MediaVideo, MediaAudio} {
+		formats, err := selectFormats(video.Formats, DownloadOptions{Media: media})
+		if err != nil {
+			continue // nothing available for this media
+		}
+		seen := make(map[string]bool)
+		for i := range formats {
+			_, ext := getContentType(formats[i].MimeType)
+			quality := getQuality(&formats[i])
+			if seen[quality+ext] {
+				continue
+			}
+			seen[quality+ext] = true
+			info.Qualities = append(info.Qualities, QualityInfo{
+				Media:     media,
+				Quality:   quality,
+				Container: ext,
+				Size:      formatSize(&formats[i]),
+			})
+		}
+	}
+
+	for _, track := range video.CaptionTracks {
+		info.Captions = append(info.Captions, CaptionInfo{
+			LanguageCode: track.LanguageCode,
+			Name:         track.Name.SimpleText,
+			Generated:    track.Kind == "asr",
+		})
+	}
+	return info
+}
+
+// parseChapters finds the chapters in a video description the same way youtube does, the timestamps must start at
+// 0:00 and count up
+func parseChapters(description string) []Chapter {
+	chapters := make([]Chapter, 0)
+	for _, line := range strings.Split(description, "\n") {
+		match := chapterPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		start, err := ParseTimestamp(match[1])
+		if err != nil {
+			continue
+		}
+		if len(chapters) == 0 && start != 0 {
+			continue // chapters have to start at the beginning of the video
+		}
+		if len(chapters) != 0 && start <= chapters[len(chapters)-1].Start {
+			continue
+		}
+		chapters = append(chapters, Chapter{Start: start, Title: strings.TrimSpace(match[2])})
+	}
+	if len(chapters) < minChapters {
+		return nil
+	}
+	return chapters
+}
@@ -0,0 +1,72 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kkdai/youtube/v2"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidPlaylist = errors.New("invalid playlist")
+var ErrInvalidSelection = errors.New("invalid selection")
+
+// GetPlaylist loads the playlist from its URL or ID
+func (c *Client) GetPlaylist(playlistURL string) (*Playlist, error) {
+	playlist, err := c.ytClient.GetPlaylist(playlistURL)
+	if err != nil {
+		if errors.Is(err, youtube.ErrInvalidPlaylist) {
+			return nil, ErrInvalidPlaylist
+		}
+		return nil, fmt.Errorf("playlist error: %w", err)
+	}
+
+	entries := make([]PlaylistEntry, 0, len(playlist.Videos))
+	for _, video := range playlist.Videos {
+		entries = append(entries, PlaylistEntry{
+			ID:       video.ID,
+			Title:    video.Title,
+			Author:   video.Author,
+			Duration: video.Duration,
+		})
+	}
+	return &Playlist{
+		ID:      playlist.ID,
+		Title:   playlist.Title,
+		Author:  playlist.Author,
+		Entries: entries,
+	}, nil
+}
+
+// Select returns the entries picked by a selection like 1-3,5. Entries are numbered from 1
+func (p *Playlist) Select(selection string) ([]PlaylistEntry, error) {
+	if strings.TrimSpace(selection) == "" {
+		return p.Entries, nil
+	}
+
+	selected := make([]PlaylistEntry, 0)
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(selection, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		start, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not an entry number", ErrInvalidSelection, part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+				return nil, fmt.Errorf("%w: %q is not a range of entries", ErrInvalidSelection, part)
+			}
+		}
+		if start < 1 || end > len(p.Entries) || start > end {
+			return nil, fmt.Errorf("%w: the playlist has entries 1-%d", ErrInvalidSelection, len(p.Entries))
+		}
+		for n := start; n <= end; n++ {
+			if !seen[n] {
+				seen[n] = true
+				selected = append(selected, p.Entries[n-1])
+			}
+		}
+	}
+	return selected, nil
+}
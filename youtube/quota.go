@@ -0,0 +1,131 @@
+package youtube
+
+import (
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"log/slog"
+	"sync"
+	"time"
+	_ "time/tzdata" // the quota resets in pacific time, which has to load even where the system has no zoneinfo
+)
+
+// searchCost is how many quota units a search.list request costs
+const searchCost = 100
+
+var ErrQuotaExhausted = errors.New("the daily YouTube search quota has been used up")
+
+// quotaLocation is where the Data API quota resets at midnight
+var quotaLocation = loadQuotaLocation()
+
+// QuotaTracker keeps count of the Data API quota spent today, alerting as thresholds are crossed. Usage is saved to
+// postgres when a database is given so restarts don't reset the count
+type QuotaTracker struct {
+	mu         *sync.Mutex
+	db         *sqlx.DB
+	limit      int
+	thresholds []float64
+	alert      func(format string, a ...any)
+
+	day     string
+	spent   int
+	alerted map[float64]bool
+}
+
+// NewQuotaTracker creates a tracker for the daily limit, alert is called the first time usage crosses each of the
+// thresholds (fractions of the limit) in a day
+func NewQuotaTracker(limit int, thresholds []float64, db *sqlx.DB, alert func(format string, a ...any)) *QuotaTracker {
+	if alert == nil {
+		alert = func(string, ...any) {}
+	}
+	return &QuotaTracker{
+		mu:         &sync.Mutex{},
+		db:         db,
+		limit:      limit,
+		thresholds: thresholds,
+		alert:      alert,
+		alerted:    make(map[float64]bool),
+	}
+}
+
+// Spend the units, returning ErrQuotaExhausted if there aren't enough left today
+func (q *QuotaTracker) Spend(units int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollover()
+
+	if q.spent+units > q.limit {
+		return ErrQuotaExhausted
+	}
+	q.spent += units
+	q.save()
+
+	for _, threshold := range q.thresholds {
+		if !q.alerted[threshold] && float64(q.spent) >= threshold*float64(q.limit) {
+			q.alerted[threshold] = true
+			q.alert("YouTube search has used %d of its %d daily quota units (%.0f%%)", q.spent, q.limit, threshold*100)
+		}
+	}
+	return nil
+}
+
+// rollover resets the count when the quota day changes, picking up where we left off from postgres.
+// The caller must hold q.mu
+func (q *QuotaTracker) rollover() {
+	day := time.Now().In(quotaLocation).Format(time.DateOnly)
+	if day == q.day {
+		return
+	}
+	q.day, q.spent = day, 0
+	q.alerted = make(map[float64]bool)
+	if q.db == nil {
+		return
+	}
+
+	query := `SELECT COALESCE((SELECT units FROM youtube_quota_usage WHERE day = $1), 0)`
+	if err := q.db.Get(&q.spent, query, day); err != nil {
+		slog.Error("failed to get youtube quota usage", "day", day, "error", err)
+	}
+	// don't repeat the alerts that went out before a restart
+	for _, threshold := range q.thresholds {
+		q.alerted[threshold] = float64(q.spent) >= threshold*float64(q.limit)
+	}
+}
+
+// save the days usage. The caller must hold q.mu
+func (q *QuotaTracker) save() {
+	if q.db == nil {
+		return
+	}
+	query := `INSERT INTO youtube_quota_usage (day, units) VALUES ($1, $2)
+				ON CONFLICT (day) DO UPDATE SET units = EXCLUDED.units`
+	if _, err := q.db.Exec(query, q.day, q.spent); err != nil {
+		slog.Error("failed to save youtube quota usage", "day", q.day, "error", err)
+	}
+}
+
+// QuotaLimitedSearch spends quota for every search made with another SearchProvider, refusing to search once the
+// quota is exhausted
+type QuotaLimitedSearch struct {
+	next    SearchProvider
+	tracker *QuotaTracker
+}
+
+func NewQuotaLimitedSearch(next SearchProvider, tracker *QuotaTracker) *QuotaLimitedSearch {
+	return &QuotaLimitedSearch{next: next, tracker: tracker}
+}
+
+func (q *QuotaLimitedSearch) Search(req SearchRequest) (YTSearchResults, error) {
+	if err := q.tracker.Spend(searchCost); err != nil {
+		return YTSearchResults{}, err
+	}
+	return q.next.Search(req)
+}
+
+// loadQuotaLocation loads pacific time, it's embedded with time/tzdata so it can't be missing
+func loadQuotaLocation() *time.Location {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
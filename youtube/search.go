@@ -0,0 +1,150 @@
+package youtube
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const DefaultSearchURL = "https://www.googleapis.com/youtube/v3/search"
+
+// maxSearchResults is the most results the Data API returns in a page
+const maxSearchResults = 50
+
+// Result types
+const (
+	SearchVideo    = "video"
+	SearchPlaylist = "playlist"
+	SearchChannel  = "channel"
+)
+
+// uploadDates are how far back each upload date filter reaches
+var uploadDates = map[string]time.Duration{
+	"hour":  time.Hour,
+	"today": 24 * time.Hour,
+	"week":  7 * 24 * time.Hour,
+	"month": 30 * 24 * time.Hour,
+	"year":  365 * 24 * time.Hour,
+}
+
+// quotaReasons are the Data API error reasons that mean the project's quota has been used up
+var quotaReasons = map[string]bool{
+	"quotaExceeded":      true,
+	"dailyLimitExceeded": true,
+}
+
+// SearchRequest describes a page of search results, empty filters are left up to the provider
+type SearchRequest struct {
+	Query string
+	// Type of result, video, playlist or channel
+	Type string
+	// Duration of videos, short (< 4m), medium (4-20m) or long (> 20m). Only videos are returned when set
+	Duration string
+	// Uploaded only returns results uploaded within the last hour, today, week, month or year. The range is
+	// resolved when the search is made, so the request stays the same however long it is kept
+	Uploaded string
+	// Order of the results, relevance, date, viewCount or rating
+	Order      string
+	MaxResults int
+	// SafeSearch is none, moderate or strict
+	SafeSearch string
+	// PageToken is the next or previous page token of an earlier search
+	PageToken string
+}
+
+// SearchProvider searches YouTube
+type SearchProvider interface {
+	Search(req SearchRequest) (YTSearchResults, error)
+}
+
+// DataAPI searches with the YouTube Data API
+type DataAPI struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewDataAPI creates a Data API search provider. The base URL defaults to the Data API's search endpoint and can be
+// pointed at a stand-in server, ie. one serving a FakeSearch
+func NewDataAPI(baseURL, apiKey string, httpClient *http.Client) *DataAPI {
+	if baseURL == "" {
+		baseURL = DefaultSearchURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &DataAPI{baseURL: baseURL, apiKey: apiKey, httpClient: httpClient}
+}
+
+func (d *DataAPI) Search(req SearchRequest) (YTSearchResults, error) {
+	reqURL, err := url.Parse(d.baseURL)
+	if err != nil {
+		return YTSearchResults{}, fmt.Errorf("invalid search url: %w", err)
+	}
+	params := reqURL.Query()
+	params.Set("part", "snippet")
+	params.Set("q", req.Query)
+	params.Set("key", d.apiKey)
+	if req.Duration != "" {
+		req.Type = SearchVideo // the api only filters videos by duration
+		params.Set("videoDuration", req.Duration)
+	}
+	setParam(params, "type", req.Type)
+	setParam(params, "order", req.Order)
+	setParam(params, "safeSearch", req.SafeSearch)
+	setParam(params, "pageToken", req.PageToken)
+	if within, ok := uploadDates[req.Uploaded]; ok {
+		params.Set("publishedAfter", time.Now().Add(-within).UTC().Format(time.RFC3339))
+	}
+	if req.MaxResults > 0 {
+		params.Set("maxResults", strconv.Itoa(min(req.MaxResults, maxSearchResults)))
+	}
+	reqURL.RawQuery = params.Encode()
+
+	resp, err := d.httpClient.Get(reqURL.String())
+	if err != nil {
+		return YTSearchResults{}, fmt.Errorf("request error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return YTSearchResults{}, getAPIError(resp)
+	}
+
+	ytResponse := YTSearchResults{}
+	if err = json.NewDecoder(resp.Body).Decode(&ytResponse); err != nil {
+		return YTSearchResults{}, fmt.Errorf("response error: %w", err)
+	}
+	return ytResponse, nil
+}
+
+// getAPIError reads the reason the Data API gave for a failed request, running out of quota is reported as
+// ErrQuotaExhausted
+func getAPIError(resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+			Errors  []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("api error: unexpected status code %d", resp.StatusCode)
+	}
+	for _, e := range body.Error.Errors {
+		if quotaReasons[e.Reason] {
+			return ErrQuotaExhausted
+		}
+	}
+	return fmt.Errorf("api error: unexpected status code %d: %s", resp.StatusCode, body.Error.Message)
+}
+
+func setParam(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
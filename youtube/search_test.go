@@ -0,0 +1,181 @@
+package youtube
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newFakeServer serves the fake search like the Data API would, recording the raw query of every request
+func newFakeServer(t *testing.T, fake *FakeSearch) (*DataAPI, *[]string) {
+	t.Helper()
+	rawQueries := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQueries = append(rawQueries, r.URL.RawQuery)
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return NewDataAPI(server.URL+"/youtube/v3/search", "test-key", server.Client()), &rawQueries
+}
+
+func TestDataAPIRequestEncoding(t *testing.T) {
+	fake := NewFakeSearch()
+	api, rawQueries := newFakeServer(t, fake)
+
+	query := "cats & dogs #1 50%/100% é"
+	if _, err := api.Search(SearchRequest{Query: query, Type: SearchPlaylist, PageToken: "CAUQAA==", MaxResults: 3}); err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+
+	if len(fake.Requests) != 1 {
+		t.Fatalf("fake received %d requests, want 1", len(fake.Requests))
+	}
+	got := fake.Requests[0]
+	if got.Query != query {
+		t.Errorf("query = %q, want %q", got.Query, query)
+	}
+	if got.Type != SearchPlaylist {
+		t.Errorf("type = %q, want %q", got.Type, SearchPlaylist)
+	}
+	if got.PageToken != "CAUQAA==" {
+		t.Errorf("page token = %q, want %q", got.PageToken, "CAUQAA==")
+	}
+	if got.MaxResults != 3 {
+		t.Errorf("max results = %d, want 3", got.MaxResults)
+	}
+
+	// the query is encoded exactly once
+	params, err := url.ParseQuery((*rawQueries)[0])
+	if err != nil {
+		t.Fatalf("invalid raw query %q: %v", (*rawQueries)[0], err)
+	}
+	if params.Get("q") != query {
+		t.Errorf("raw q decodes to %q, want %q", params.Get("q"), query)
+	}
+	if params.Get("key") != "test-key" || params.Get("part") != "snippet" {
+		t.Errorf("raw query %q is missing the key or part", (*rawQueries)[0])
+	}
+	for _, unset := range []string{"order", "safeSearch", "videoDuration", "publishedAfter"} {
+		if params.Has(unset) {
+			t.Errorf("raw query %q sets %s, want it left out", (*rawQueries)[0], unset)
+		}
+	}
+}
+
+func TestDataAPIDurationOnlyReturnsVideos(t *testing.T) {
+	fake := NewFakeSearch()
+	api, _ := newFakeServer(t, fake)
+
+	if _, err := api.Search(SearchRequest{Query: "lofi", Type: SearchChannel, Duration: "long"}); err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	if got := fake.Requests[0]; got.Type != SearchVideo || got.Duration != "long" {
+		t.Errorf("type = %q and duration = %q, want %q and %q", got.Type, got.Duration, SearchVideo, "long")
+	}
+}
+
+func TestDataAPIResponseMapping(t *testing.T) {
+	fake := NewFakeSearch()
+	playlist := NewVideoResult("", "Mix", "Someone")
+	playlist.ID.Kind = "youtube#playlist"
+	playlist.ID.PlaylistID = "PL123"
+	fake.Add("music",
+		NewVideoResult("aaaaaaaaaaa", "First", "Someone"),
+		playlist,
+		NewVideoResult("bbbbbbbbbbb", "Second", "Someone"),
+		NewVideoResult("ccccccccccc", "Third", "Someone"),
+	)
+	api, _ := newFakeServer(t, fake)
+
+	first, err := api.Search(SearchRequest{Query: "Music", MaxResults: 2})
+	if err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	if len(first.Items) != 2 {
+		t.Fatalf("first page has %d items, want 2", len(first.Items))
+	}
+	if first.Items[0].ID.VideoID != "aaaaaaaaaaa" || first.Items[0].Snippet.Title != "First" {
+		t.Errorf("first item = %+v, want video aaaaaaaaaaa titled First", first.Items[0].ID)
+	}
+	if first.Items[1].ID.Kind != "youtube#playlist" || first.Items[1].ID.PlaylistID != "PL123" {
+		t.Errorf("second item = %+v, want playlist PL123", first.Items[1].ID)
+	}
+	if first.NextPageToken == "" || first.PrevPageToken != "" {
+		t.Errorf("first page tokens = next %q prev %q, want only a next token", first.NextPageToken, first.PrevPageToken)
+	}
+	if first.PageInfo.TotalResults != 4 || first.PageInfo.ResultsPerPage != 2 {
+		t.Errorf("page info = %+v, want 4 results 2 per page", first.PageInfo)
+	}
+
+	second, err := api.Search(SearchRequest{Query: "music", MaxResults: 2, PageToken: first.NextPageToken})
+	if err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	if len(second.Items) != 2 || second.Items[0].ID.VideoID != "bbbbbbbbbbb" {
+		t.Fatalf("second page = %+v, want bbbbbbbbbbb first", second.Items)
+	}
+	if second.NextPageToken != "" || second.PrevPageToken == "" {
+		t.Errorf("second page tokens = next %q prev %q, want only a prev token", second.NextPageToken, second.PrevPageToken)
+	}
+
+	videos, err := api.Search(SearchRequest{Query: "music", Type: SearchVideo})
+	if err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	for _, item := range videos.Items {
+		if item.ID.VideoID == "" {
+			t.Errorf("video search returned %+v", item.ID)
+		}
+	}
+}
+
+func TestDataAPIEmptyResults(t *testing.T) {
+	api, _ := newFakeServer(t, NewFakeSearch())
+
+	results, err := api.Search(SearchRequest{Query: "nothing matches this"})
+	if err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	if len(results.Items) != 0 || results.NextPageToken != "" || results.PrevPageToken != "" {
+		t.Errorf("results = %+v, want no items or page tokens", results)
+	}
+}
+
+func TestDataAPIErrorStatus(t *testing.T) {
+	fake := NewFakeSearch()
+	fake.Err = http.ErrHandlerTimeout
+	api, _ := newFakeServer(t, fake)
+
+	if _, err := api.Search(SearchRequest{Query: "anything"}); err == nil {
+		t.Error("Search() error = nil, want an error for a failed request")
+	}
+}
+
+func TestDataAPIQuotaExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":403,"message":"The request cannot be completed because you have exceeded your quota.",` +
+			`"errors":[{"message":"quota","domain":"youtube.quota","reason":"quotaExceeded"}]}}`))
+	}))
+	defer server.Close()
+
+	api := NewDataAPI(server.URL, "test-key", server.Client())
+	if _, err := api.Search(SearchRequest{Query: "anything"}); !errors.Is(err, ErrQuotaExhausted) {
+		t.Errorf("Search() error = %v, want %v", err, ErrQuotaExhausted)
+	}
+}
+
+func TestSearchCacheKeyUploaded(t *testing.T) {
+	req := SearchRequest{Query: "  Lofi   Beats ", Uploaded: "week"}
+	same := SearchRequest{Query: "lofi beats", Uploaded: "week"}
+	if searchCacheKey(req) != searchCacheKey(same) {
+		t.Errorf("equivalent searches have different keys %q and %q", searchCacheKey(req), searchCacheKey(same))
+	}
+	other := SearchRequest{Query: "lofi beats", Uploaded: "today"}
+	if searchCacheKey(req) == searchCacheKey(other) {
+		t.Errorf("searches with different upload dates share the key %q", searchCacheKey(req))
+	}
+}
@@ -6,15 +6,24 @@ import (
 )
 
 type YTSearchResults struct {
-	Items []YTSearchItem `json:"items"`
+	Items         []YTSearchItem `json:"items"`
+	NextPageToken string         `json:"nextPageToken,omitempty"`
+	PrevPageToken string         `json:"prevPageToken,omitempty"`
+	PageInfo      struct {
+		// TotalResults is an approximation, the api caps it at 1,000,000
+		TotalResults   int `json:"totalResults"`
+		ResultsPerPage int `json:"resultsPerPage"`
+	} `json:"pageInfo"`
 }
 
 type YTSearchItem struct {
 	Kind string `json:"kind"`
 	Etag string `json:"etag"`
 	ID   struct {
-		Kind    string `json:"kind"`
-		VideoID string `json:"videoId"`
+		Kind       string `json:"kind"`
+		VideoID    string `json:"videoId,omitempty"`
+		PlaylistID string `json:"playlistId,omitempty"`
+		ChannelID  string `json:"channelId,omitempty"`
 	} `json:"id"`
 	Snippet struct {
 		PublishedAt time.Time `json:"publishedAt"`
@@ -34,8 +43,66 @@ type YTSearchItem struct {
 	} `json:"snippet"`
 }
 
-type File struct {
+type VideoInfo struct {
+	ID           string
+	Title        string
+	Author       string
+	ChannelID    string
+	Duration     time.Duration
+	Views        int
+	PublishDate  time.Time
+	ThumbnailURL string
+	// Qualities the video can be downloaded in, best first
+	Qualities []QualityInfo
+	Chapters  []Chapter
+	Captions  []CaptionInfo
+}
+
+type QualityInfo struct {
+	Media   Media
+	Quality string
+	// Container is the file extension of the stream, ie. .mp4
+	Container string
+	// Size of the stream in bytes, 0 when unknown
+	Size int64
+}
+
+type Chapter struct {
+	Start time.Duration
+	Title string
+}
+
+type CaptionInfo struct {
+	LanguageCode string
 	Name         string
-	ContentType  string
+	// Generated is set for captions youtube generated automatically
+	Generated bool
+}
+
+type Playlist struct {
+	ID      string
+	Title   string
+	Author  string
+	Entries []PlaylistEntry
+}
+
+type PlaylistEntry struct {
+	ID       string
+	Title    string
+	Author   string
+	Duration time.Duration
+}
+
+type File struct {
+	Name        string
+	ContentType string
+	// Quality of the stream that was downloaded
+	Quality string
+	// Size of the stream in bytes, estimated from the bitrate if youtube doesn't report it. 0 when unknown
+	Size int64
+	// Downgraded is set when the requested quality was too large to upload and a smaller stream was used
+	Downgraded bool
+	// Cached is set when the file is served from the download cache
+	Cached       bool
 	ReaderCloser io.ReadCloser
 }
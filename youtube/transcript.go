@@ -0,0 +1,179 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kkdai/youtube/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrNoCaptions = errors.New("video has no captions")
+var ErrLanguageUnavailable = errors.New("captions aren't available in that language")
+
+// TranscriptFormat is the file format a transcript is written in
+type TranscriptFormat string
+
+var (
+	TranscriptText TranscriptFormat = "text"
+	TranscriptSRT  TranscriptFormat = "srt"
+	TranscriptVTT  TranscriptFormat = "vtt"
+)
+
+type Caption struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+type Transcript struct {
+	VideoID  string
+	Title    string
+	Language string
+	Captions []Caption
+}
+
+// timedText is the json3 format of youtube's caption tracks
+type timedText struct {
+	Events []struct {
+		StartMs    int64 `json:"tStartMs"`
+		DurationMs int64 `json:"dDurationMs"`
+		Segs       []struct {
+			Text string `json:"utf8"`
+		} `json:"segs"`
+	} `json:"events"`
+}
+
+// GetTranscript fetches the video's caption track in the language, or the video's default captions if the language
+// is empty. Captions youtube generated are only used if there is nothing better
+func (c *Client) GetTranscript(videoID, language string) (*Transcript, error) {
+	video, err := c.getVideo(videoID)
+	if err != nil {
+		return nil, err
+	}
+	track, err := selectCaptionTrack(video.CaptionTracks, language)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.captionClient().Get(track.BaseURL + "&fmt=json3")
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("caption error: unexpected status code %d", resp.StatusCode)
+	}
+
+	var text timedText
+	if err = json.NewDecoder(resp.Body).Decode(&text); err != nil {
+		return nil, fmt.Errorf("response error: %w", err)
+	}
+
+	transcript := &Transcript{VideoID: video.ID, Title: video.Title, Language: track.LanguageCode}
+	for _, event := range text.Events {
+		var b strings.Builder
+		for _, seg := range event.Segs {
+			b.WriteString(seg.Text)
+		}
+		line := strings.TrimSpace(b.String())
+		if line == "" {
+			continue // events without text only position the captions
+		}
+		start := time.Duration(event.StartMs) * time.Millisecond
+		transcript.Captions = append(transcript.Captions, Caption{
+			Start: start,
+			End:   start + time.Duration(event.DurationMs)*time.Millisecond,
+			Text:  line,
+		})
+	}
+	if len(transcript.Captions) == 0 {
+		return nil, ErrNoCaptions
+	}
+	return transcript, nil
+}
+
+// Window returns the captions shown between start and end, a zero end runs to the end of the video
+func (t *Transcript) Window(start, end time.Duration) *Transcript {
+	window := &Transcript{VideoID: t.VideoID, Title: t.Title, Language: t.Language}
+	for _, caption := range t.Captions {
+		if caption.End > start && (end == 0 || caption.Start < end) {
+			window.Captions = append(window.Captions, caption)
+		}
+	}
+	return window
+}
+
+// Format writes the transcript in the format, returning it along with its content type and file extension
+func (t *Transcript) Format(format TranscriptFormat) (string, string, string) {
+	var b strings.Builder
+	switch format {
+	case TranscriptSRT:
+		for i, caption := range t.Captions {
+			fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1,
+				formatCueTime(caption.Start, ","), formatCueTime(caption.End, ","), caption.Text)
+		}
+		return b.String(), "application/x-subrip", ".srt"
+	case TranscriptVTT:
+		b.WriteString("WEBVTT\n\n")
+		for _, caption := range t.Captions {
+			fmt.Fprintf(&b, "%s --> %s\n%s\n\n",
+				formatCueTime(caption.Start, "."), formatCueTime(caption.End, "."), caption.Text)
+		}
+		return b.String(), "text/vtt", ".vtt"
+	default:
+		for _, caption := range t.Captions {
+			fmt.Fprintf(&b, "[%s] %s\n", formatCueTime(caption.Start, ".")[:8], caption.Text)
+		}
+		return b.String(), "text/plain", ".txt"
+	}
+}
+
+func (c *Client) captionClient() *http.Client {
+	if c.ytClient.HTTPClient != nil {
+		return c.ytClient.HTTPClient
+	}
+	return c.httpClient
+}
+
+// selectCaptionTrack picks the track in the language, preferring captions that weren't generated
+func selectCaptionTrack(tracks []youtube.CaptionTrack, language string) (*youtube.CaptionTrack, error) {
+	if len(tracks) == 0 {
+		return nil, ErrNoCaptions
+	}
+
+	var generated *youtube.CaptionTrack
+	for i := range tracks {
+		track := &tracks[i]
+		if language != "" && !strings.EqualFold(track.LanguageCode, language) &&
+			!strings.EqualFold(track.Name.SimpleText, language) {
+			continue
+		}
+		if track.Kind != "asr" {
+			return track, nil
+		}
+		if generated == nil {
+			generated = track
+		}
+	}
+	if generated != nil {
+		return generated, nil
+	}
+	if language == "" {
+		return &tracks[0], nil
+	}
+
+	available := make([]string, len(tracks))
+	for i, track := range tracks {
+		available[i] = track.LanguageCode
+	}
+	return nil, fmt.Errorf("%w, available languages are %s", ErrLanguageUnavailable, strings.Join(available, ", "))
+}
+
+// formatCueTime formats the time the way subtitle files expect, ie. 01:02:03.456
+func formatCueTime(d time.Duration, sep string) string {
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, sep, d.Milliseconds()%1000)
+}
@@ -1,98 +1,163 @@
 package youtube
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Zach51920/discord-bot/config"
+	"github.com/jmoiron/sqlx"
 	"github.com/kkdai/youtube/v2"
-	"io"
 	"net/http"
-	"net/url"
 	"strings"
 )
 
 type Client struct {
 	httpClient *http.Client
 	ytClient   youtube.Client
-	apiKey     string
+	searcher   SearchProvider
+	downloads  *DownloadCache
 }
 
-func New() *Client {
+// New creates a client that searches with the Data API. YOUTUBE_SEARCH_URL can point the searches at a stand-in.
+// Searches are limited to the daily quota and cached as configured, alert is used to warn about quota usage.
+// Downloads are cached on disk when a cache size is configured
+func New(cfg config.YouTubeConfig, db *sqlx.DB, alert func(format string, a ...any)) (*Client, error) {
+	var searcher SearchProvider = NewDataAPI(config.GetStringDefault("YOUTUBE_SEARCH_URL", DefaultSearchURL),
+		config.GetString("GOOGLE_API_KEY"), nil)
+	if cfg.DailyQuota > 0 {
+		tracker := NewQuotaTracker(cfg.DailyQuota, cfg.QuotaAlertThresholds, db, alert)
+		searcher = NewQuotaLimitedSearch(searcher, tracker)
+	}
+	if cfg.SearchCacheTTL > 0 {
+		var cacheDB *sqlx.DB
+		if cfg.PersistSearchCache {
+			cacheDB = db
+		}
+		searcher = NewCachedSearch(searcher, cfg.SearchCacheTTL, cacheDB)
+	}
+
+	client := NewWithSearchProvider(searcher)
+	if cfg.DownloadCacheMB > 0 {
+		downloads, err := NewDownloadCache(cfg.DownloadCacheDirectory, cfg.DownloadCacheMB<<20)
+		if err != nil {
+			return nil, fmt.Errorf("create download cache: %w", err)
+		}
+		client.downloads = downloads
+	}
+	return client, nil
+}
+
+func NewWithSearchProvider(searcher SearchProvider) *Client {
 	return &Client{
 		httpClient: http.DefaultClient,
 		ytClient:   youtube.Client{},
-		apiKey:     config.GetString("GOOGLE_API_KEY"),
+		searcher:   searcher,
+	}
+}
+
+// Close removes the cached downloads
+func (c *Client) Close() error {
+	if c.downloads == nil {
+		return nil
 	}
+	return c.downloads.Close()
 }
 
-const searchURL = "https://www.googleapis.com/youtube/v3/search"
+// Search YouTube with the client's search provider
+func (c *Client) Search(req SearchRequest) (YTSearchResults, error) {
+	return c.searcher.Search(req)
+}
 
-func (c *Client) Search(query string) (YTSearchResults, error) {
-	req := buildURL(searchURL, map[string]any{
-		"part": "snippet",
-		"q":    url.QueryEscape(query),
-		"key":  c.apiKey,
-	})
-	resp, err := c.httpClient.Get(req)
+func (c *Client) GetVideo(videoID string) (*VideoInfo, error) {
+	video, err := c.getVideo(videoID)
 	if err != nil {
-		return YTSearchResults{}, fmt.Errorf("request error: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return mapVideoInfo(video), nil
+}
 
-	if resp.StatusCode != 200 {
-		return YTSearchResults{}, fmt.Errorf("api error: unexpected status code %d", resp.StatusCode)
+// Download streams the video in the requested format. The stream is picked from the formats that match the
+// requested media and quality, preferring the best of them that fits within the size limit. Downloads of the same
+// stream and clip are served from the download cache when it's enabled
+func (c *Client) Download(videoID string, opts DownloadOptions) (*File, error) {
+	video, err := c.getVideo(videoID)
+	if err != nil {
+		return nil, err
 	}
 
-	var body []byte
-	if body, err = io.ReadAll(resp.Body); err != nil {
-		return YTSearchResults{}, fmt.Errorf("response error: %w", err)
+	if opts.Media == "" {
+		opts.Media = MediaVideo
 	}
-	ytResponse := YTSearchResults{}
-	if err = json.Unmarshal(body, &ytResponse); err != nil {
-		return YTSearchResults{}, fmt.Errorf("response error: %w", err)
-	}
-	return ytResponse, nil
-}
 
-func (c *Client) Download(videoID string) (*File, error) {
-	video, err := c.ytClient.GetVideo(videoID)
-	if err != nil {
-		if errors.Is(err, youtube.ErrInvalidCharactersInVideoID) || errors.Is(err, youtube.ErrVideoIDMinLength) {
-			return nil, errors.New("invalid video ID")
+	// a clip only takes up a fraction of the stream, scale the size limit to match
+	ratio := 1.0
+	if !opts.Clip.IsZero() {
+		if opts.Clip, err = opts.Clip.validate(video.Duration); err != nil {
+			return nil, err
 		}
-		var playbackErr *youtube.ErrPlayabiltyStatus
-		if errors.As(err, &playbackErr) {
-			return nil, errors.New(playbackErr.Reason)
+		if video.Duration > 0 {
+			ratio = float64(opts.Clip.End-opts.Clip.Start) / float64(video.Duration)
+			opts.MaxSize = int64(float64(opts.MaxSize) / ratio)
 		}
-		return nil, fmt.Errorf("video error: %w", err)
 	}
 
-	formats := video.Formats.WithAudioChannels() // only get videos with audio
-	if len(formats) == 0 {
-		return nil, fmt.Errorf("no video formats contain audio channels")
+	format, downgraded, err := pickFormat(video.Formats, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	key := downloadCacheKey(videoID, format, opts)
+	if c.downloads != nil {
+		if file, ok := c.downloads.Get(key); ok {
+			file.Downgraded = downgraded
+			return file, nil
+		}
 	}
 
-	stream, _, err := c.ytClient.GetStream(video, &formats[0])
+	stream, _, err := c.ytClient.GetStream(video, format)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get stream: %w", err)
 	}
 
-	return &File{
-		Name:         strings.Join(strings.Split(video.Title, " "), "-") + ".mp4",
-		ContentType:  video.Formats[0].MimeType,
+	name := strings.Join(strings.Split(video.Title, " "), "-")
+	contentType, ext := getContentType(format.MimeType)
+	if !opts.Clip.IsZero() {
+		media := opts.Media
+		if downgraded && format.QualityLabel == "" {
+			media = MediaAudio // fell back to audio only
+		}
+		clipped, clipType, clipExt, err := clipStream(stream, opts.Clip, media)
+		if err != nil {
+			stream.Close()
+			return nil, err
+		}
+		stream, contentType, ext = clipped, clipType, clipExt
+		name += "-clip"
+	}
+	file := &File{
+		Name:         name + ext,
+		ContentType:  contentType,
+		Quality:      getQuality(format),
+		Size:         int64(float64(formatSize(format)) * ratio),
+		Downgraded:   downgraded,
 		ReaderCloser: stream,
-	}, nil
+	}
+	if c.downloads != nil {
+		file = c.downloads.Wrap(key, file)
+	}
+	return file, nil
 }
 
-func buildURL(baseUrl string, params map[string]any) string {
-	builder := strings.Builder{}
-	builder.WriteString(baseUrl)
-
-	prefix := "?"
-	for k, v := range params {
-		builder.WriteString(fmt.Sprintf("%s%s=%v", prefix, k, v))
-		prefix = "&"
+func (c *Client) getVideo(videoID string) (*youtube.Video, error) {
+	video, err := c.ytClient.GetVideo(videoID)
+	if err != nil {
+		if errors.Is(err, youtube.ErrInvalidCharactersInVideoID) || errors.Is(err, youtube.ErrVideoIDMinLength) {
+			return nil, errors.New("invalid video ID")
+		}
+		var playbackErr *youtube.ErrPlayabiltyStatus
+		if errors.As(err, &playbackErr) {
+			return nil, errors.New(playbackErr.Reason)
+		}
+		return nil, fmt.Errorf("video error: %w", err)
 	}
-	return builder.String()
+	return video, nil
 }